@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// InDesign's XML formats (IDML's designmap.xml, and later snippets and
+// InCopy stories) start with an "aid" processing instruction such as:
+//
+//	<?aid style="50" type="document" readerVersion="6.0" featureSet="257" product="19.0(46)" ?>
+//
+// The product attribute is the version of the application that wrote it.
+
+// errNoAIDInstruction is returned when no <?aid ...?> instruction is found.
+var errNoAIDInstruction = errors.New("no <?aid?> processing instruction found")
+
+// AIDInstruction holds the attributes of an <?aid ...?> instruction.
+type AIDInstruction struct {
+	// Type is the document type, e.g. "document" or "snippet".
+	Type string
+	// Product is the writing application's version, e.g. "19.0(46)".
+	Product string
+	// ReaderVersion is the oldest application version that can read it.
+	ReaderVersion string
+	// Major and Minor are decoded from Product.
+	Major uint32
+	Minor uint32
+}
+
+// sniffLimit is the most we read while looking for the <?aid?> instruction.
+// It sits in the prolog, before the root element, so this is plenty even
+// for files hundreds of megabytes long.
+const sniffLimit = 64 << 10
+
+// aidAttrPattern matches one key="value" pair of a processing instruction.
+var aidAttrPattern = regexp.MustCompile(`(\w+)\s*=\s*"([^"]*)"`)
+
+// productPattern matches "19.0(46)" or "19.0".
+var productPattern = regexp.MustCompile(`^(\d+)(?:\.(\d+))?`)
+
+// parseAIDInstruction decodes the body of an <?aid ...?> instruction.
+func parseAIDInstruction(inst []byte) (AIDInstruction, error) {
+	var aid AIDInstruction
+	for _, m := range aidAttrPattern.FindAllSubmatch(inst, -1) {
+		value := string(m[2])
+		switch string(m[1]) {
+		case "type":
+			aid.Type = value
+		case "product":
+			aid.Product = value
+		case "readerVersion":
+			aid.ReaderVersion = value
+		}
+	}
+
+	m := productPattern.FindStringSubmatch(aid.Product)
+	if m == nil {
+		return aid, fmt.Errorf("could not read product version %q", aid.Product)
+	}
+	major, err := strconv.ParseUint(m[1], 10, 32)
+	if err != nil {
+		return aid, fmt.Errorf("could not read product version %q: %w", aid.Product, err)
+	}
+	aid.Major = uint32(major)
+	if m[2] != "" {
+		if minor, err := strconv.ParseUint(m[2], 10, 32); err == nil {
+			aid.Minor = uint32(minor)
+		}
+	}
+	return aid, nil
+}
+
+// readAIDInstruction reads XML from r until it finds the <?aid ...?>
+// instruction. It stops at the root element (the instruction always comes
+// before it) and never reads more than sniffLimit bytes.
+func readAIDInstruction(r io.Reader) (AIDInstruction, error) {
+	dec := xml.NewDecoder(io.LimitReader(decodeBOM(r), sniffLimit))
+	// We only need the ASCII prolog, so any declared encoding will do.
+	dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			return AIDInstruction{}, errNoAIDInstruction
+		}
+		if err != nil {
+			return AIDInstruction{}, fmt.Errorf("could not read XML: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.ProcInst:
+			if t.Target == "aid" {
+				return parseAIDInstruction(t.Inst)
+			}
+		case xml.StartElement:
+			return AIDInstruction{}, errNoAIDInstruction
+		}
+	}
+}
+
+// decodeBOM returns a reader producing UTF-8. UTF-16 input (marked by a
+// byte order mark) is transcoded on the fly; a UTF-8 BOM is dropped.
+func decodeBOM(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	bom, _ := br.Peek(3)
+	switch {
+	case bytes.HasPrefix(bom, []byte{0xEF, 0xBB, 0xBF}):
+		br.Discard(3)
+		return br
+	case bytes.HasPrefix(bom, []byte{0xFF, 0xFE}):
+		br.Discard(2)
+		return &utf16Reader{r: br, order: binary.LittleEndian}
+	case bytes.HasPrefix(bom, []byte{0xFE, 0xFF}):
+		br.Discard(2)
+		return &utf16Reader{r: br, order: binary.BigEndian}
+	default:
+		return br
+	}
+}
+
+// utf16Reader transcodes a UTF-16 stream to UTF-8.
+type utf16Reader struct {
+	r     *bufio.Reader
+	order binary.ByteOrder
+	buf   []byte
+}
+
+func (u *utf16Reader) Read(p []byte) (int, error) {
+	for len(u.buf) == 0 {
+		r, err := u.readRune()
+		if err != nil {
+			return 0, err
+		}
+		u.buf = utf8.AppendRune(u.buf, r)
+	}
+	n := copy(p, u.buf)
+	u.buf = u.buf[n:]
+	return n, nil
+}
+
+// readRune decodes one code point, combining surrogate pairs.
+func (u *utf16Reader) readRune() (rune, error) {
+	var unit [2]byte
+	if _, err := io.ReadFull(u.r, unit[:]); err != nil {
+		return 0, err
+	}
+	r1 := rune(u.order.Uint16(unit[:]))
+	if !utf16.IsSurrogate(r1) {
+		return r1, nil
+	}
+	if _, err := io.ReadFull(u.r, unit[:]); err != nil {
+		return 0, err
+	}
+	return utf16.DecodeRune(r1, rune(u.order.Uint16(unit[:]))), nil
+}
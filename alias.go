@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// resolveVersion turns a version typed by a user into a Version. It is
+// deliberately forgiving: on top of what parseVersion accepts, it ignores
+// case, spaces, dashes and underscores, and "Adobe", "InDesign", "ID" and
+// "v" prefixes, so "cc2019", "CC-2019", "ID19", "v19.0", "InDesign 2024"
+// and "cs6" all work. A marketing name may carry a minor version, as in
+// "2024.1". Every version that comes from flags or configuration
+// goes through here.
+func resolveVersion(input string) (Version, error) {
+	s := normalizeVersionAlias(input)
+	if s == "" {
+		return Version{}, fmt.Errorf("empty InDesign version")
+	}
+
+	// Marketing names, compared without spaces: "cc2019" == "CC 2019",
+	// optionally followed by a minor version: "2024.1" == 19.1.
+	name, minor, hasMinor := strings.Cut(s, ".")
+	for _, e := range catalog.Versions {
+		if e.Name == "" || compactAlias(e.Name) != name {
+			continue
+		}
+		if !hasMinor {
+			return Version{Major: e.Major}, nil
+		}
+		if v, err := parseVersion(fmt.Sprintf("%d.%s", e.Major, minor)); err == nil {
+			return v, nil
+		}
+	}
+	if v, err := parseVersion(s); err == nil {
+		return v, nil
+	}
+
+	if suggestions := suggestVersions(s); len(suggestions) > 0 {
+		return Version{}, fmt.Errorf("unknown InDesign version %q; did you mean %s?", input, strings.Join(suggestions, " or "))
+	}
+	return Version{}, fmt.Errorf("unknown InDesign version %q (try e.g. \"2024\", \"CC 2019\", \"CS6\" or \"19.2\")", input)
+}
+
+// versionAliasPrefixes are stripped from the front of an alias, in order.
+var versionAliasPrefixes = []string{"adobe", "indesign", "id", "v"}
+
+// normalizeVersionAlias lowercases s, drops separators and known prefixes.
+// Dots are kept, since they separate numeric versions.
+func normalizeVersionAlias(s string) string {
+	s = compactAlias(s)
+	for _, p := range versionAliasPrefixes {
+		rest, ok := strings.CutPrefix(s, p)
+		if !ok {
+			continue
+		}
+		// "id" and "v" must be followed by a number ("ID19", "v19.0"),
+		// so a name that merely starts with those letters survives.
+		if (p == "id" || p == "v") && (rest == "" || !unicode.IsDigit(rune(rest[0]))) {
+			continue
+		}
+		s = rest
+	}
+	return s
+}
+
+// compactAlias lowercases s and removes spaces, dashes and underscores.
+func compactAlias(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' || r == '_' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, s)
+}
+
+// suggestVersions returns the known version names closest to an
+// unrecognized alias, for "did you mean" errors.
+func suggestVersions(alias string) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+	// Newest first, so that ties favour recent versions ("cs7" suggests
+	// "CS6" before "CS2").
+	var candidates []candidate
+	for i := len(catalog.Versions) - 1; i >= 0; i-- {
+		e := catalog.Versions[i]
+		name := Version{Major: e.Major}.Name()
+		for _, form := range []string{compactAlias(name), strconv.Itoa(int(e.Major))} {
+			candidates = append(candidates, candidate{name, editDistance(alias, form)})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].distance < candidates[j].distance })
+
+	// Only suggest names that are a typo or two away.
+	const maxDistance = 2
+	var names []string
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		if c.distance > maxDistance || len(names) == 3 {
+			break
+		}
+		if !seen[c.name] {
+			seen[c.name] = true
+			names = append(names, strconv.Quote(c.name))
+		}
+	}
+	return names
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
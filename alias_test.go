@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveVersion(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Version
+	}{
+		{"cc2019", Version{Major: 14}},
+		{"CC-2019", Version{Major: 14}},
+		{"CC_2019", Version{Major: 14}},
+		{"19", Version{Major: 19}},
+		{"ID19", Version{Major: 19}},
+		{"v19.0", Version{Major: 19}},
+		{"v19.2.1", Version{19, 2, 1}},
+		{"InDesign 2024", Version{Major: 19}},
+		{"Adobe InDesign CC 2019", Version{Major: 14}},
+		{"cs6", Version{Major: 8}},
+		{" 2025 ", Version{Major: 20}},
+		{"2024.1", Version{19, 1, 0}},
+		{"InDesign 2024.1.2", Version{19, 1, 2}},
+		{"CC 2019.3", Version{14, 3, 0}},
+	}
+	for _, tt := range tests {
+		got, err := resolveVersion(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("resolveVersion(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestResolveVersionSuggestions(t *testing.T) {
+	tests := []struct {
+		in, suggestion string
+	}{
+		{"cs7", `"CS6"`},
+		{"cs66", `"CS6"`},
+		{"CC 2109", `"CC 2019"`},
+		{"2204", `"2024"`},
+	}
+	for _, tt := range tests {
+		_, err := resolveVersion(tt.in)
+		if err == nil || !strings.Contains(err.Error(), "did you mean") || !strings.Contains(err.Error(), tt.suggestion) {
+			t.Errorf("resolveVersion(%q) error = %v, want a suggestion of %s", tt.in, err, tt.suggestion)
+		}
+	}
+	if _, err := resolveVersion("photoshop"); err == nil || strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("resolveVersion(photoshop) error = %v, want no suggestion", err)
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// A book (.indb) is an InDesign database like a document, with the
+// "BOOKBOOK" type. Its chapter list is not documented, but every chapter
+// is stored with its full path, so we recover the chapters by scanning the
+// file for path strings that end in ".indd", in ASCII and in UTF-16.
+
+// maxBookSize guards against reading huge files as books; real books are
+// a few hundred kilobytes at most.
+const maxBookSize = 64 << 20
+
+// maxChapterPath is the longest path string we reconstruct.
+const maxChapterPath = 1024
+
+// chapterSuffix is the extension every chapter path ends with.
+const chapterSuffix = ".indd"
+
+// BookChapter is a chapter document referenced by a book.
+type BookChapter struct {
+	// RecordedPath is the path as stored in the book.
+	RecordedPath string
+	// Path is where the chapter was found on disk, or "" if missing.
+	Path string
+	// Header is the chapter's active header, when it could be read.
+	Header Header
+	// Err is set when the chapter is missing or unreadable.
+	Err error
+}
+
+// getBookChapters reads a book file and returns the chapters it references,
+// in the order they first appear, resolved against the local disk.
+func getBookChapters(bookPath string) ([]BookChapter, error) {
+	info, err := os.Stat(bookPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open book: %w", err)
+	}
+	if info.Size() > maxBookSize {
+		return nil, fmt.Errorf("book is too large (%d bytes)", info.Size())
+	}
+	data, err := os.ReadFile(bookPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read book: %w", err)
+	}
+
+	var chapters []BookChapter
+	seen := make(map[string]bool)
+	for _, recorded := range findChapterPaths(data) {
+		ch := BookChapter{RecordedPath: recorded}
+		ch.Path = resolveChapter(recorded, bookPath)
+		if ch.Path == "" {
+			ch.Err = fmt.Errorf("chapter not found")
+		} else {
+			if seen[ch.Path] {
+				continue
+			}
+			seen[ch.Path] = true
+			ch.Header, ch.Err = getInDesignVersion(ch.Path)
+		}
+		chapters = append(chapters, ch)
+	}
+	return chapters, nil
+}
+
+// findChapterPaths extracts every distinct string ending in ".indd" from
+// the raw book data, in file order.
+func findChapterPaths(data []byte) []string {
+	type found struct {
+		offset int
+		path   string
+	}
+	var all []found
+	add := func(offset int, p string) {
+		if len(p) > len(chapterSuffix) {
+			all = append(all, found{offset, p})
+		}
+	}
+
+	// Fold ASCII case only, so offsets in lower match offsets in data.
+	lower := make([]byte, len(data))
+	for i, b := range data {
+		if b >= 'A' && b <= 'Z' {
+			b += 'a' - 'A'
+		}
+		lower[i] = b
+	}
+
+	// ASCII / UTF-8: walk back from each suffix over printable bytes.
+	for _, end := range suffixEnds(lower, []byte(chapterSuffix)) {
+		start := end - len(chapterSuffix)
+		for start > 0 && end-start < maxChapterPath && isPathByte(data[start-1]) {
+			start--
+		}
+		// The path is stored after its length. When that length happens to
+		// be a printable byte, the scan above includes it: drop it. A
+		// leading separator is kept, since it is far more likely the root
+		// of the path than a length of 47 or 92.
+		if b := data[start]; int(b) == end-start-1 && b != '/' && b != '\\' {
+			start++
+		}
+		add(start, string(data[start:end]))
+	}
+
+	// UTF-16, both byte orders: walk back over printable code units.
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		suffix := encodeUTF16(chapterSuffix, order)
+		for _, end := range suffixEnds(lower, suffix) {
+			start := end - len(suffix)
+			for start >= 2 && end-start < 2*maxChapterPath && isPathRune(rune(order.Uint16(data[start-2:]))) {
+				start -= 2
+			}
+			add(start, decodeUTF16(data[start:end], order))
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool { return all[i].offset < all[j].offset })
+	var paths []string
+	seen := make(map[string]bool)
+	for _, f := range all {
+		if !seen[f.path] {
+			seen[f.path] = true
+			paths = append(paths, f.path)
+		}
+	}
+	return paths
+}
+
+// suffixEnds returns the end offset of every occurrence of suffix in data.
+func suffixEnds(data, suffix []byte) []int {
+	var ends []int
+	for off := 0; ; {
+		i := bytes.Index(data[off:], suffix)
+		if i < 0 {
+			return ends
+		}
+		off += i + len(suffix)
+		ends = append(ends, off)
+	}
+}
+
+func isPathByte(b byte) bool {
+	return b >= 0x20 && b < 0x7f
+}
+
+func isPathRune(r rune) bool {
+	return r >= 0x20 && !utf16.IsSurrogate(r) && unicode.IsPrint(r)
+}
+
+func encodeUTF16(s string, order binary.ByteOrder) []byte {
+	u := utf16.Encode([]rune(s))
+	b := make([]byte, 2*len(u))
+	for i, c := range u {
+		order.PutUint16(b[2*i:], c)
+	}
+	return b
+}
+
+func decodeUTF16(b []byte, order binary.ByteOrder) string {
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = order.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(u))
+}
+
+// resolveChapter finds a chapter on disk: at its recorded path, or next to
+// the book (books are usually moved together with their chapters).
+func resolveChapter(recorded, bookPath string) string {
+	local := linkLocalPath(recorded)
+	if filepath.IsAbs(local) && fileExists(local) {
+		return local
+	}
+	name := linkBaseName(local)
+	if p := filepath.Join(filepath.Dir(bookPath), name); fileExists(p) {
+		return p
+	}
+	return ""
+}
+
+// describeBookFile reads every chapter of a book and prints a report like
+// openFile's. The book is opened in the oldest installed version that can
+// open all chapters, so the required version is the newest of the book
+// and its chapters.
+func describeBookFile(absPath string, target launchTarget) (launchTarget, error) {
+	fmt.Printf("Book Version: %s (Major: %d)\n", target.version.Name(), target.version.Major)
+
+	chapters, err := getBookChapters(absPath)
+	if err != nil {
+		return target, err
+	}
+
+	fmt.Printf("Chapters (%d):\n", len(chapters))
+	var missing []string
+	for i, ch := range chapters {
+		name := linkBaseName(linkLocalPath(ch.RecordedPath))
+		if ch.Err != nil {
+			fmt.Printf("  %d. %s: %v\n", i+1, name, ch.Err)
+			missing = append(missing, name)
+			continue
+		}
+		version := ch.Header.Version()
+		fmt.Printf("  %d. %s: %s (Major: %d)\n", i+1, name, version.Name(), version.Major)
+		if version.Compare(target.version) > 0 {
+			target.version = version
+		}
+	}
+	if len(missing) > 0 {
+		target.warn(fmt.Sprintf("%d chapter(s) could not be checked: %s", len(missing), strings.Join(missing, ", ")))
+	}
+	fmt.Printf("Book requires: %s (Major: %d)\n", target.version.Name(), target.version.Major)
+	return target, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindChapterPaths(t *testing.T) {
+	// pathOf returns an absolute path of exactly n bytes.
+	pathOf := func(dir string, n int) string {
+		return dir + strings.Repeat("c", n-len(dir)-len(".indd")) + ".indd"
+	}
+	short := "/Books/Intro.indd"
+	printable := pathOf("/Books/Annual/", 0x41)
+	rooted := pathOf("/Books/", '/'+1)
+	utf16Path := `C:\Books\Kapitel 1.indd`
+
+	var data []byte
+	data = append(data, 0x00, 0x00)
+	data = append(data, byte(len(short)))
+	data = append(data, short...)
+	// A length byte that is printable ('A') right before the path.
+	data = append(data, 0xff, byte(len(printable)))
+	data = append(data, printable...)
+	// A little-endian length, so the byte before the path is zero and the
+	// path's own leading '/' equals the length of the rest.
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(rooted)))
+	data = append(data, rooted...)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(utf16Path)))
+	data = append(data, encodeUTF16(utf16Path, binary.LittleEndian)...)
+	// The same chapter again is reported once.
+	data = append(data, 0x00, byte(len(short)))
+	data = append(data, short...)
+
+	got := findChapterPaths(data)
+	want := []string{short, printable, rooted, utf16Path}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findChapterPaths = %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BundleInfo is what the launcher needs from a macOS app's Info.plist.
+type BundleInfo struct {
+	// Identifier is CFBundleIdentifier, e.g. "com.adobe.InDesign".
+	Identifier string
+	// ShortVersion is CFBundleShortVersionString, e.g. "19.2".
+	ShortVersion string
+	// Executable is CFBundleExecutable, the binary in Contents/MacOS.
+	Executable string
+}
+
+// readBundleInfo reads Contents/Info.plist of the .app bundle app.
+func readBundleInfo(fsys fs.FS, app string) (BundleInfo, error) {
+	data, err := fs.ReadFile(fsys, path.Join(app, "Contents", "Info.plist"))
+	if err != nil {
+		return BundleInfo{}, fmt.Errorf("could not read Info.plist: %w", err)
+	}
+	v, err := parsePlist(data)
+	if err != nil {
+		return BundleInfo{}, err
+	}
+	dict, ok := v.(map[string]any)
+	if !ok {
+		return BundleInfo{}, fmt.Errorf("Info.plist is a %T, not a dictionary", v)
+	}
+	str := func(key string) string {
+		s, _ := dict[key].(string)
+		return strings.TrimSpace(s)
+	}
+	return BundleInfo{
+		Identifier:   str("CFBundleIdentifier"),
+		ShortVersion: str("CFBundleShortVersionString"),
+		Executable:   str("CFBundleExecutable"),
+	}, nil
+}
+
+// bundleVersionPattern matches the leading numeric part of a bundle
+// version: "19.2", "19.2.1" or "8.0.2.413".
+var bundleVersionPattern = regexp.MustCompile(`^(\d+)(?:\.(\d+))?(?:\.(\d+))?`)
+
+// Version returns the InDesign version of the bundle.
+func (b BundleInfo) Version() (Version, error) {
+	m := bundleVersionPattern.FindStringSubmatch(b.ShortVersion)
+	if m == nil {
+		return Version{}, fmt.Errorf("unknown bundle version %q", b.ShortVersion)
+	}
+	var parts [3]uint32
+	for i, p := range m[1:] {
+		if p == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid bundle version %q: %w", b.ShortVersion, err)
+		}
+		parts[i] = uint32(n)
+	}
+	if parts[0] == 0 || parts[0] > maxPlausibleMajor {
+		return Version{}, fmt.Errorf("implausible bundle version %q", b.ShortVersion)
+	}
+	return Version{Major: parts[0], Minor: parts[1], Patch: parts[2]}, nil
+}
+
+// isInDesignBundle reports whether id is the bundle identifier of an
+// InDesign release in the catalog.
+func isInDesignBundle(id string) bool {
+	for _, e := range catalog.Versions {
+		if e.BundleID != "" && strings.EqualFold(e.BundleID, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// darwinDiscoverers is the macOS setup on fsys: the user catalog, then
+// every InDesign app bundle in /Applications, classified by its Info.plist.
+// It lives here rather than in find_app_darwin.go so it can be tested on
+// any OS.
+func darwinDiscoverers(fsys fs.FS, installs []UserInstall, debug bool) []Discoverer {
+	return []Discoverer{
+		userConfigDiscoverer{fsys: fsys, installs: installs},
+		bundleDiscoverer{fsys: fsys, dir: "Applications", debug: debug},
+	}
+}
+
+// bundleDiscoverer finds InDesign apps in a folder such as "Applications"
+// by their Info.plist rather than their names, so renamed and localized
+// folders still work. It looks at .app bundles in the folder and one level
+// below it ("Adobe InDesign 2024/Adobe InDesign 2024.app"). Only a bundle
+// whose Info.plist cannot be read is classified by its standard install
+// folder name instead. A missing folder is not an error.
+type bundleDiscoverer struct {
+	fsys fs.FS
+	dir  string
+	// debug prints why InDesign-looking bundles were skipped.
+	debug bool
+}
+
+func (d bundleDiscoverer) Name() string { return "app bundles in " + hostPath(d.dir) }
+
+func (d bundleDiscoverer) Discover() ([]Install, error) {
+	entries, err := fs.ReadDir(d.fsys, d.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", hostPath(d.dir), err)
+	}
+	var apps []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		name := path.Join(d.dir, e.Name())
+		if isAppBundle(e.Name()) {
+			apps = append(apps, name)
+			continue
+		}
+		children, err := fs.ReadDir(d.fsys, name)
+		if err != nil {
+			continue
+		}
+		for _, c := range children {
+			if c.IsDir() && isAppBundle(c.Name()) {
+				apps = append(apps, path.Join(name, c.Name()))
+			}
+		}
+	}
+
+	logf := func(format string, args ...any) {
+		if d.debug {
+			fmt.Fprintf(os.Stderr, "... discovery: "+format+"\n", args...)
+		}
+	}
+	skip := func(app string, err error) { logf("skipping %s: %v", hostPath(app), err) }
+	var installs []Install
+	for _, app := range apps {
+		info, err := readBundleInfo(d.fsys, app)
+		if err != nil {
+			if major, ok := d.majorFromName(app); ok {
+				logf("%s: %v; using its folder name", hostPath(app), err)
+				installs = append(installs, Install{Major: major, Path: hostPath(app)})
+				continue
+			}
+			skip(app, err)
+			continue
+		}
+		if !isInDesignBundle(info.Identifier) {
+			continue
+		}
+		v, err := info.Version()
+		if err != nil {
+			skip(app, err)
+			continue
+		}
+		// An app whose binary is gone (a half-removed install) cannot start.
+		if info.Executable != "" && !isFileFS(d.fsys, path.Join(app, "Contents", "MacOS", info.Executable)) {
+			skip(app, fmt.Errorf("executable %q is missing", info.Executable))
+			continue
+		}
+		installs = append(installs, Install{Major: v.Major, Path: hostPath(app)})
+	}
+	return installs, nil
+}
+
+// majorFromName classifies app by the name of its install folder, for a
+// standard layout such as "Adobe InDesign 2024/Adobe InDesign 2024.app" or
+// a bare "Adobe InDesign 2024.app".
+func (d bundleDiscoverer) majorFromName(app string) (uint32, bool) {
+	folder := path.Base(path.Dir(app))
+	if path.Dir(app) == d.dir {
+		folder = path.Base(app)
+		folder = folder[:len(folder)-len(".app")]
+	}
+	if path.Base(app) != appRelPath("darwin", folder) {
+		return 0, false
+	}
+	return majorForInstallFolder("darwin", folder)
+}
+
+func isAppBundle(name string) bool {
+	return strings.HasSuffix(strings.ToLower(name), ".app")
+}
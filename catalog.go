@@ -0,0 +1,255 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// The version catalog describes every InDesign release the launcher knows:
+// its marketing name, lifecycle dates, COM ProgID suffix, install folders
+// and bundle ID. The built-in catalog is embedded from catalog.json; a user
+// catalog with the same layout can override entries or add new releases
+// without a new launcher binary.
+
+//go:embed catalog.json
+var builtinCatalogData []byte
+
+// userCatalogName is the user catalog's file name inside the launcher's
+// config folder (see userCatalogPath).
+const userCatalogName = "versions.json"
+
+// CatalogEntry describes one InDesign major version.
+type CatalogEntry struct {
+	// Major is the version number stored in files, e.g. 19.
+	Major uint32 `json:"major"`
+	// Name is the marketing name, e.g. "2024" or "CC 2019".
+	Name string `json:"name"`
+	// Released is the day the version shipped; Adobe releases the next
+	// year's version in the autumn, so 2024 shipped in October 2023.
+	Released Date `json:"released"`
+	// EndOfSupport is when Adobe stopped supporting the version, roughly
+	// when the version two releases newer shipped. It is zero while the
+	// version is still supported.
+	EndOfSupport Date `json:"endOfSupport"`
+	// RunsOnCurrentOS tells, per GOOS, whether the version still runs on
+	// current releases of that OS. Missing entries mean unknown. Windows 10
+	// and 11 still run the 32-bit CS releases, from CS3 on; macOS dropped
+	// 32-bit apps in 10.15, and releases before 2021 do not start on
+	// current macOS.
+	RunsOnCurrentOS map[string]bool `json:"runsOnCurrentOS,omitempty"`
+	// ProgID is the suffix of the Windows COM ProgID
+	// "InDesign.Application.<ProgID>", e.g. "CC.2019".
+	ProgID string `json:"progID,omitempty"`
+	// BundleID is the macOS bundle identifier.
+	BundleID string `json:"bundleID,omitempty"`
+	// Folders lists the install folder names per GOOS, e.g.
+	// {"windows": ["Adobe InDesign 2024"]}.
+	Folders map[string][]string `json:"folders,omitempty"`
+}
+
+// Catalog is the list of known versions, indexed by major.
+type Catalog struct {
+	// MinVersion is the oldest version that is launched at all; older
+	// installs are ignored during discovery. It goes through resolveVersion,
+	// so "CS6" and "8" both work. Empty means no minimum.
+	MinVersion string         `json:"minVersion,omitempty"`
+	Versions   []CatalogEntry `json:"versions"`
+	// Installs lists InDesign copies outside the standard folders; only
+	// the user catalog sets it (see userConfigDiscoverer).
+	Installs []UserInstall `json:"installs,omitempty"`
+
+	byMajor map[uint32]*CatalogEntry
+}
+
+// catalog is the built-in catalog, merged with the user catalog once main
+// calls loadUserCatalog.
+var catalog *Catalog
+
+// init loads the built-in catalog before anything else looks up a version.
+// The user catalog is left to main so tests only see the embedded one.
+func init() {
+	var err error
+	catalog, err = parseCatalog(builtinCatalogData)
+	if err != nil {
+		panic(fmt.Sprintf("built-in version catalog: %v", err))
+	}
+}
+
+// loadUserCatalog merges the user catalog into catalog. A broken user
+// catalog is reported and ignored rather than stopping the launcher.
+func loadUserCatalog() {
+	if path := userCatalogPath(); path != "" {
+		if err := catalog.mergeFile(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Ignoring user version catalog: %v\n", err)
+		}
+	}
+}
+
+// userCatalogPath returns where the user catalog lives, e.g.
+// ~/Library/Application Support/indesign-launcher/versions.json on macOS or
+// %AppData%\indesign-launcher\versions.json on Windows.
+func userCatalogPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "indesign-launcher", userCatalogName)
+}
+
+// parseCatalog decodes a catalog file.
+func parseCatalog(data []byte) (*Catalog, error) {
+	var c Catalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("could not parse catalog: %w", err)
+	}
+	c.index()
+	for _, e := range c.Versions {
+		if e.Major == 0 {
+			return nil, fmt.Errorf("catalog entry %q has no major version", e.Name)
+		}
+	}
+	return &c, nil
+}
+
+// mergeFile merges the catalog at path into c. A missing file is not an error.
+func (c *Catalog) mergeFile(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read '%s': %w", path, err)
+	}
+	user, err := parseCatalog(data)
+	if err != nil {
+		return fmt.Errorf("'%s': %w", path, err)
+	}
+	c.merge(user)
+	return nil
+}
+
+// merge overrides c with the entries of other. Fields set in other win;
+// folder lists and OS support are replaced per OS. Unknown majors are added.
+func (c *Catalog) merge(other *Catalog) {
+	if other.MinVersion != "" {
+		c.MinVersion = other.MinVersion
+	}
+	c.Installs = append(c.Installs, other.Installs...)
+	for _, o := range other.Versions {
+		e, ok := c.byMajor[o.Major]
+		if !ok {
+			c.Versions = append(c.Versions, o)
+			c.index()
+			continue
+		}
+		if o.Name != "" {
+			e.Name = o.Name
+		}
+		if !o.Released.IsZero() {
+			e.Released = o.Released
+		}
+		if !o.EndOfSupport.IsZero() {
+			e.EndOfSupport = o.EndOfSupport
+		}
+		for goos, runs := range o.RunsOnCurrentOS {
+			if e.RunsOnCurrentOS == nil {
+				e.RunsOnCurrentOS = make(map[string]bool)
+			}
+			e.RunsOnCurrentOS[goos] = runs
+		}
+		if o.ProgID != "" {
+			e.ProgID = o.ProgID
+		}
+		if o.BundleID != "" {
+			e.BundleID = o.BundleID
+		}
+		for goos, folders := range o.Folders {
+			if e.Folders == nil {
+				e.Folders = make(map[string][]string)
+			}
+			e.Folders[goos] = folders
+		}
+	}
+}
+
+// index sorts the entries by major and rebuilds the lookup table.
+func (c *Catalog) index() {
+	sort.SliceStable(c.Versions, func(i, j int) bool { return c.Versions[i].Major < c.Versions[j].Major })
+	c.byMajor = make(map[uint32]*CatalogEntry, len(c.Versions))
+	for i := range c.Versions {
+		c.byMajor[c.Versions[i].Major] = &c.Versions[i]
+	}
+}
+
+// lookup returns the entry for a major version.
+func (c *Catalog) lookup(major uint32) (*CatalogEntry, bool) {
+	e, ok := c.byMajor[major]
+	return e, ok
+}
+
+// majorForName returns the major version with the given marketing name,
+// comparing names case-insensitively.
+func (c *Catalog) majorForName(name string) (uint32, bool) {
+	for _, e := range c.Versions {
+		if e.Name != "" && strings.EqualFold(e.Name, name) {
+			return e.Major, true
+		}
+	}
+	return 0, false
+}
+
+// majorForFolder returns the major version installed in a folder named
+// name on goos, comparing names case-insensitively.
+func (c *Catalog) majorForFolder(goos, name string) (uint32, bool) {
+	for _, e := range c.Versions {
+		for _, folder := range e.Folders[goos] {
+			if strings.EqualFold(folder, name) {
+				return e.Major, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// runCatalog implements the "catalog" command: it prints the effective
+// version catalog and where the user catalog is read from.
+func runCatalog(args []string) error {
+	fs := flag.NewFlagSet("catalog", flag.ExitOnError)
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return fmt.Errorf("usage: %s", commands["catalog"].usage)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "MAJOR\tNAME\tRELEASED\tEND OF SUPPORT\tRUNS ON CURRENT OS\tPROGID\tFOLDERS (%s)\n", runtime.GOOS)
+	for _, e := range catalog.Versions {
+		eos := e.EndOfSupport.String()
+		if eos == "" {
+			eos = "supported"
+		}
+		runs := "unknown"
+		if r, ok := e.RunsOnCurrentOS[runtime.GOOS]; ok {
+			runs = map[bool]string{true: "yes", false: "no"}[r]
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", e.Major, Version{Major: e.Major}.Name(), e.Released, eos, runs, e.ProgID, strings.Join(e.Folders[runtime.GOOS], ", "))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if catalog.MinVersion != "" {
+		fmt.Printf("Minimum version: %s\n", catalog.MinVersion)
+	}
+	fmt.Printf("User catalog: %s\n", userCatalogPath())
+	return nil
+}
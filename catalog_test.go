@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestCatalogMerge(t *testing.T) {
+	c, err := parseCatalog(builtinCatalogData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := parseCatalog([]byte(`{"versions": [
+		{"major": 19, "folders": {"windows": ["InDesign 2024 (Custom)"]}},
+		{"major": 22, "name": "2027", "progID": "2027"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.merge(user)
+
+	e, ok := c.lookup(19)
+	if !ok || e.Name != "2024" || e.ProgID != "2024" {
+		t.Errorf("major 19 = %+v, want the built-in name and ProgID kept", e)
+	}
+	if major, ok := c.majorForFolder("windows", "InDesign 2024 (custom)"); !ok || major != 19 {
+		t.Errorf("custom folder = %d, %v; want 19", major, ok)
+	}
+	if major, ok := c.majorForFolder("darwin", "Adobe InDesign 2024"); !ok || major != 19 {
+		t.Errorf("darwin folder = %d, %v; want 19 (not overridden)", major, ok)
+	}
+	if e, ok := c.lookup(22); !ok || e.Name != "2027" {
+		t.Errorf("major 22 = %+v, %v; want the added entry", e, ok)
+	}
+	if last := c.Versions[len(c.Versions)-1]; last.Major != 22 {
+		t.Errorf("last entry is %d, want entries sorted by major", last.Major)
+	}
+}
+
+func TestCatalogRejectsMissingMajor(t *testing.T) {
+	if _, err := parseCatalog([]byte(`{"versions": [{"name": "2030"}]}`)); err == nil {
+		t.Error("accepted an entry without a major version")
+	}
+}
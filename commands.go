@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"sort"
+)
+
+// --- Subcommands ---
+
+// command is a tool invoked as "indesign-launcher <name> [args]"
+// instead of opening a file.
+type command struct {
+	// usage is the synopsis shown in the help output.
+	usage string
+	// description is a one-line summary shown in the help output.
+	description string
+	// run executes the command with the arguments that follow its name.
+	run func(args []string) error
+}
+
+// commands maps each subcommand name to its implementation.
+var commands map[string]command
+
+// init fills the command table. It runs in init() because the commands
+// themselves refer back to the table for their usage lines.
+func init() {
+	commands = map[string]command{
+		"catalog": {
+			usage:       "catalog",
+			description: "List the known InDesign versions and where the user catalog is read from",
+			run:         runCatalog,
+		},
+		"fonts": {
+			usage:       "fonts <file> [-check=false]",
+			description: "List the document's fonts and whether each one is installed",
+			run:         runFonts,
+		},
+		"history": {
+			usage:       "history <file>",
+			description: "List every save event with the exact InDesign build and platform",
+			run:         runHistory,
+		},
+		"info": {
+			usage:       "info <file>",
+			description: "Show the document's version, page count and page size",
+			run:         runInfo,
+		},
+		"inspect": {
+			usage:       "inspect [-hex] [-bytes N] <file>",
+			description: "Show every header field of both master pages and explain parse failures",
+			run:         runInspect,
+		},
+		"links": {
+			usage:       "links <file>",
+			description: "List the document's linked assets and whether each one resolves on disk",
+			run:         runLinks,
+		},
+		"locks": {
+			usage:       "locks <folder> [-older-than 24h] [-remove [-include-old]]",
+			description: "List .idlk lock files under a folder and remove the orphaned ones",
+			run:         runLocks,
+		},
+		"thumbnail": {
+			usage:       "thumbnail <file> [-o out.jpg|out.png] [-size N] [-page N]",
+			description: "Write the embedded preview image, optionally resized",
+			run:         runThumbnail,
+		},
+	}
+}
+
+// parseCommandFlags parses fs from args, allowing flags to appear before
+// or after the positional arguments, and returns the positional ones.
+func parseCommandFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// printCommandUsage lists the available subcommands.
+func printCommandUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	log.Println("Commands:")
+	for _, name := range names {
+		log.Printf("  %s\n", commands[name].usage)
+		log.Printf("    \t%s\n", commands[name].description)
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Discovery is split into backends that each know one way of finding
+// InDesign: the catalog's standard install paths, a scan of the install
+// folder, the Windows registry and installs listed in the user catalog.
+// Every backend reads the file system through an fs.FS and the registry
+// through the Registry interface, so all of them can be tested with fake
+// trees on any OS. findAllInstalledVersions merges their results.
+
+// Install is one InDesign installation found by a Discoverer.
+type Install struct {
+	Major uint32
+	// Path is what launchApp runs: the .app bundle on macOS, the .exe (or
+	// the registered command line) on Windows.
+	Path string
+	// Explicit is set for installs the user listed; they are never
+	// dropped by the server/beta keyword filter (see shouldIgnore).
+	Explicit bool
+}
+
+// Discoverer finds installed InDesign versions one way.
+type Discoverer interface {
+	// Name describes the backend in messages, e.g. "registry".
+	Name() string
+	// Discover returns the installs it found. It may return installs
+	// together with an error when only part of the search failed.
+	Discover() ([]Install, error)
+}
+
+// debugDiscovery makes discoverers explain installs they skip; main sets
+// it from -debug.
+var debugDiscovery bool
+
+// findAllInstalledVersions runs the discoverers of this OS and returns a
+// map of {majorVersion: appPath}. Installs older than floor are left out
+// and returned separately in ignored, so callers can explain why.
+func findAllInstalledVersions(floor Version) (found, ignored map[uint32]string, err error) {
+	discoverers := defaultDiscoverers()
+	if len(discoverers) == 0 {
+		return nil, nil, fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
+	}
+	found, err = discoverAll(discoverers)
+	if err != nil {
+		return nil, nil, err
+	}
+	return found, applyMinVersion(found, floor), nil
+}
+
+// discoverAll merges the results of several discoverers. Earlier
+// discoverers win when two find the same major. A failing discoverer is
+// reported and skipped; discoverAll only fails when every one failed.
+func discoverAll(discoverers []Discoverer) (map[uint32]string, error) {
+	found := make(map[uint32]string)
+	var errs []error
+	for _, d := range discoverers {
+		installs, err := d.Discover()
+		if err != nil {
+			err = fmt.Errorf("%s: %w", d.Name(), err)
+			fmt.Fprintf(os.Stderr, "... discovery: %v\n", err)
+			errs = append(errs, err)
+		}
+		for _, in := range installs {
+			if _, ok := found[in.Major]; ok {
+				continue
+			}
+			if !in.Explicit && shouldIgnore(in.Path) {
+				if debugDiscovery {
+					fmt.Fprintf(os.Stderr, "... discovery: ignoring %s (server, debug or prerelease build)\n", in.Path)
+				}
+				continue
+			}
+			found[in.Major] = in.Path
+		}
+	}
+	if len(found) == 0 && len(errs) == len(discoverers) {
+		return nil, errors.Join(errs...)
+	}
+	return found, nil
+}
+
+// hostFS is the real file system as an fs.FS. Names are absolute OS paths
+// in slash form without the leading slash, e.g.
+// "Applications/Adobe InDesign 2024" or "C:/Program Files/Adobe".
+type hostFS struct{}
+
+// Open opens the file with the given name.
+func (hostFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	return os.Open(hostPath(name))
+}
+
+// hostPath turns an fs.FS name into an OS path.
+func hostPath(name string) string {
+	p := filepath.FromSlash(name)
+	if filepath.VolumeName(p) == "" {
+		p = string(filepath.Separator) + p
+	}
+	return p
+}
+
+// fsName turns an absolute OS path into an fs.FS name.
+func fsName(osPath string) string {
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Clean(osPath)), "/")
+}
+
+// appRelPath returns where the application sits inside an install folder
+// on goos.
+func appRelPath(goos, folder string) string {
+	if goos == "darwin" {
+		return folder + ".app"
+	}
+	return "InDesign.exe"
+}
+
+// catalogPathDiscoverer checks the catalog's install folders for goos
+// under each root, e.g. "C:/Program Files/Adobe/Adobe InDesign 2024".
+type catalogPathDiscoverer struct {
+	fsys  fs.FS
+	goos  string
+	roots []string
+}
+
+func (d catalogPathDiscoverer) Name() string { return "standard locations" }
+
+func (d catalogPathDiscoverer) Discover() ([]Install, error) {
+	var installs []Install
+	for _, e := range catalog.Versions {
+		for _, folder := range e.Folders[d.goos] {
+			for _, root := range d.roots {
+				name := path.Join(root, folder, appRelPath(d.goos, folder))
+				if _, err := fs.Stat(d.fsys, name); err == nil {
+					installs = append(installs, Install{Major: e.Major, Path: hostPath(name)})
+				}
+			}
+		}
+	}
+	return installs, nil
+}
+
+// folderScanDiscoverer lists an install folder such as "Applications" and
+// recognizes "Adobe InDesign <version>" folders, including versions that
+// are newer than the catalog. A missing folder is not an error.
+type folderScanDiscoverer struct {
+	fsys fs.FS
+	goos string
+	dir  string
+}
+
+func (d folderScanDiscoverer) Name() string { return "folder scan of " + hostPath(d.dir) }
+
+func (d folderScanDiscoverer) Discover() ([]Install, error) {
+	entries, err := fs.ReadDir(d.fsys, d.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", hostPath(d.dir), err)
+	}
+	var installs []Install
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		major, ok := majorForInstallFolder(d.goos, entry.Name())
+		if !ok {
+			continue
+		}
+		name := path.Join(d.dir, entry.Name(), appRelPath(d.goos, entry.Name()))
+		if _, err := fs.Stat(d.fsys, name); err == nil {
+			installs = append(installs, Install{Major: major, Path: hostPath(name)})
+		}
+	}
+	return installs, nil
+}
+
+// installFolderPrefix starts the name of every standard install folder.
+const installFolderPrefix = "Adobe InDesign "
+
+// majorForInstallFolder returns the major version installed in a folder,
+// from the catalog or, for unknown folders, from the version in its name.
+func majorForInstallFolder(goos, folder string) (uint32, bool) {
+	if major, ok := catalog.majorForFolder(goos, folder); ok {
+		return major, true
+	}
+	if len(folder) <= len(installFolderPrefix) || !strings.EqualFold(folder[:len(installFolderPrefix)], installFolderPrefix) {
+		return 0, false
+	}
+	v, err := resolveVersion(folder[len(installFolderPrefix):])
+	if err != nil {
+		return 0, false
+	}
+	return v.Major, true
+}
+
+// Registry reads the Windows registry.
+type Registry interface {
+	// ClassesRootValue returns the default value of a key under
+	// HKEY_CLASSES_ROOT, e.g. `InDesign.Application.2024\CLSID`.
+	ClassesRootValue(key string) (string, error)
+}
+
+// registryDiscoverer follows each catalog ProgID to its COM server:
+// InDesign.Application.<ProgID>\CLSID → CLSID\<clsid>\LocalServer32. For
+// old versions of InDesign this may fail, as the registry layout has
+// changed over time.
+type registryDiscoverer struct {
+	reg Registry
+}
+
+func (d registryDiscoverer) Name() string { return "registry" }
+
+func (d registryDiscoverer) Discover() ([]Install, error) {
+	var installs []Install
+	for _, e := range catalog.Versions {
+		if e.ProgID == "" {
+			continue
+		}
+		clsid, err := d.reg.ClassesRootValue(fmt.Sprintf(`InDesign.Application.%s\CLSID`, e.ProgID))
+		if err != nil || clsid == "" {
+			continue
+		}
+		command, err := d.reg.ClassesRootValue(fmt.Sprintf(`CLSID\%s\LocalServer32`, clsid))
+		if err != nil || command == "" {
+			continue
+		}
+		installs = append(installs, Install{Major: e.Major, Path: command})
+	}
+	return installs, nil
+}
+
+// UserInstall is an install listed in the user catalog, for InDesign
+// copies outside the standard folders.
+type UserInstall struct {
+	// Version is resolved with resolveVersion, e.g. "2024" or "CC 2019".
+	Version string `json:"version"`
+	// Path is the application, e.g. `D:\Apps\InDesign 2024\InDesign.exe`.
+	Path string `json:"path"`
+}
+
+// userConfigDiscoverer returns the installs listed in the user catalog
+// that exist on disk.
+type userConfigDiscoverer struct {
+	fsys     fs.FS
+	installs []UserInstall
+}
+
+func (d userConfigDiscoverer) Name() string { return "user catalog" }
+
+func (d userConfigDiscoverer) Discover() ([]Install, error) {
+	var installs []Install
+	var errs []error
+	for _, u := range d.installs {
+		v, err := resolveVersion(u.Version)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("install '%s': %w", u.Path, err))
+			continue
+		}
+		if _, err := fs.Stat(d.fsys, fsName(u.Path)); err != nil {
+			errs = append(errs, fmt.Errorf("install '%s': %w", u.Path, err))
+			continue
+		}
+		installs = append(installs, Install{Major: v.Major, Path: u.Path, Explicit: true})
+	}
+	return installs, errors.Join(errs...)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"id-launcher/internal/fixture"
+)
+
+// fakeRegistry maps HKEY_CLASSES_ROOT keys to their default values.
+type fakeRegistry map[string]string
+
+func (r fakeRegistry) ClassesRootValue(key string) (string, error) {
+	if v, ok := r[key]; ok {
+		return v, nil
+	}
+	return "", fmt.Errorf("key %s not found", key)
+}
+
+// failingDiscoverer always fails.
+type failingDiscoverer struct{}
+
+func (failingDiscoverer) Name() string                 { return "failing" }
+func (failingDiscoverer) Discover() ([]Install, error) { return nil, errors.New("boom") }
+
+func TestDiscoverDarwin(t *testing.T) {
+	info := func(version, exe string) *fstest.MapFile {
+		return &fstest.MapFile{Data: fixture.BinaryPlist(map[string]any{
+			"CFBundleIdentifier": "com.adobe.InDesign", "CFBundleShortVersionString": version, "CFBundleExecutable": exe,
+		})}
+	}
+	fsys := fstest.MapFS{
+		// The folder says 2024, the bundle says 2025: the bundle wins.
+		"Applications/Adobe InDesign 2024/Adobe InDesign 2024.app/Contents/Info.plist":                info("20.1", "Adobe InDesign 2025"),
+		"Applications/Adobe InDesign 2024/Adobe InDesign 2024.app/Contents/MacOS/Adobe InDesign 2025": {},
+		// Half-removed: rejected by its bundle, not brought back by its name.
+		"Applications/Adobe InDesign 2023/Adobe InDesign 2023.app/Contents/Info.plist": info("18.5", "Adobe InDesign 2023"),
+		// Unreadable Info.plist: classified by the folder name.
+		"Applications/Adobe InDesign CC 2019/Adobe InDesign CC 2019.app/Contents/Info.plist": {},
+		// Not in the catalog yet, and unreadable: recognized by its name.
+		"Applications/Adobe InDesign 2031/Adobe InDesign 2031.app/Contents/Info.plist": {Data: []byte("garbage")},
+		// Folder without the app, and unrelated apps.
+		"Applications/Adobe InDesign 2022/Uninstall.app/Contents/Info.plist":                                 {},
+		"Applications/Safari.app/Contents/Info.plist":                                                        {},
+		"Applications/Adobe InDesign 2025 Prerelease/Adobe InDesign 2025 Prerelease.app/Contents/Info.plist": {},
+		// Listed in the user catalog.
+		"Volumes/Archive/InDesign CS6.app/Contents/Info.plist": info("8.0", ""),
+	}
+	user := []UserInstall{{Version: "CS6", Path: "/Volumes/Archive/InDesign CS6.app"}}
+	found, err := discoverAll(darwinDiscoverers(fsys, user, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[uint32]string{
+		8:  "InDesign CS6.app",
+		14: "Adobe InDesign CC 2019.app",
+		20: "Adobe InDesign 2024.app",
+		26: "Adobe InDesign 2031.app",
+	}
+	if len(found) != len(want) {
+		t.Errorf("found %v, want majors 8, 14, 20 and 26", found)
+	}
+	for major, app := range want {
+		if got := found[major]; filepath.Base(got) != app {
+			t.Errorf("major %d = %q, want %s", major, got, app)
+		}
+	}
+}
+
+func TestDiscoverWindows(t *testing.T) {
+	fsys := fstest.MapFS{
+		"C:/Program Files/Adobe/Adobe InDesign 2024/InDesign.exe":        {},
+		"C:/Program Files (x86)/Adobe/Adobe InDesign CS5/InDesign.exe":   {},
+		"C:/Program Files/Adobe/Adobe InDesign Server 2024/InDesign.exe": {},
+	}
+	reg := fakeRegistry{
+		`InDesign.Application.2024\CLSID`:    "{AAAA}",
+		`CLSID\{AAAA}\LocalServer32`:         `C:\Elsewhere\InDesign.exe`,
+		`InDesign.Application.CC.2019\CLSID`: "{BBBB}",
+		`CLSID\{BBBB}\LocalServer32`:         `D:\Adobe\InDesign CC 2019\InDesign.exe`,
+		`InDesign.Application.2025\CLSID`:    "{CCCC}",
+		`CLSID\{CCCC}\LocalServer32`:         `C:\Adobe InDesign 2025 Beta\InDesign.exe`,
+		`InDesign.Application.2023\CLSID`:    "{DDDD}", // no server
+	}
+	found, err := discoverAll([]Discoverer{
+		catalogPathDiscoverer{fsys: fsys, goos: "windows", roots: []string{"C:/Program Files/Adobe", "C:/Program Files (x86)/Adobe"}},
+		registryDiscoverer{reg: reg},
+		folderScanDiscoverer{fsys: fsys, goos: "windows", dir: "C:/Program Files/Adobe"},
+		folderScanDiscoverer{fsys: fsys, goos: "windows", dir: "D:/Missing/Adobe"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 3 {
+		t.Errorf("found %v, want majors 7, 14 and 19", found)
+	}
+	// The standard location wins over the registry.
+	if !strings.HasSuffix(filepath.ToSlash(found[19]), "C:/Program Files/Adobe/Adobe InDesign 2024/InDesign.exe") {
+		t.Errorf("major 19 = %q, want the Program Files install", found[19])
+	}
+	if !strings.HasSuffix(filepath.ToSlash(found[7]), "Program Files (x86)/Adobe/Adobe InDesign CS5/InDesign.exe") {
+		t.Errorf("major 7 = %q, want the (x86) install", found[7])
+	}
+	if found[14] != `D:\Adobe\InDesign CC 2019\InDesign.exe` {
+		t.Errorf("major 14 = %q, want the registry command", found[14])
+	}
+}
+
+func TestDiscoverUserConfig(t *testing.T) {
+	fsys := fstest.MapFS{"opt/indesign/InDesign.exe": {}}
+	d := userConfigDiscoverer{fsys: fsys, installs: []UserInstall{
+		{Version: "InDesign 2024", Path: "/opt/indesign/InDesign.exe"},
+		{Version: "2023", Path: "/opt/missing/InDesign.exe"},
+		{Version: "cs7", Path: "/opt/indesign/InDesign.exe"},
+	}}
+	installs, err := d.Discover()
+	if len(installs) != 1 || installs[0].Major != 19 {
+		t.Errorf("installs = %v, want only 2024", installs)
+	}
+	if err == nil || !strings.Contains(err.Error(), "missing") || !strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("err = %v, want the missing file and the bad version reported", err)
+	}
+}
+
+func TestDiscoverUserConfigNotFiltered(t *testing.T) {
+	fsys := fstest.MapFS{"opt/InDesign 2025 Beta/InDesign.exe": {}}
+	reg := fakeRegistry{
+		`InDesign.Application.2024\CLSID`: "{AAAA}",
+		`CLSID\{AAAA}\LocalServer32`:      `C:\Adobe InDesign Server 2024\InDesign.exe`,
+	}
+	found, err := discoverAll([]Discoverer{
+		userConfigDiscoverer{fsys: fsys, installs: []UserInstall{{Version: "2025", Path: "/opt/InDesign 2025 Beta/InDesign.exe"}}},
+		registryDiscoverer{reg: reg},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The listed beta is kept; the discovered server is still filtered.
+	if len(found) != 1 || found[20] != "/opt/InDesign 2025 Beta/InDesign.exe" {
+		t.Errorf("found %v, want only the user's 2025 beta", found)
+	}
+}
+
+func TestDiscoverAllFailures(t *testing.T) {
+	if _, err := discoverAll([]Discoverer{failingDiscoverer{}}); err == nil {
+		t.Error("no error when every discoverer failed")
+	}
+	fsys := fstest.MapFS{"Applications/Adobe InDesign 2024/Adobe InDesign 2024.app/Contents/Info.plist": {}}
+	found, err := discoverAll([]Discoverer{
+		failingDiscoverer{},
+		folderScanDiscoverer{fsys: fsys, goos: "darwin", dir: "Applications"},
+	})
+	if err != nil || len(found) != 1 {
+		t.Errorf("found %v, %v; want the working discoverer's result", found, err)
+	}
+}
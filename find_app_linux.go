@@ -0,0 +1,18 @@
+//go:build linux
+
+package main
+
+import "os"
+
+// defaultDiscoverers is the Linux setup: the user catalog, then every Wine
+// prefix (plain Wine, CrossOver, Bottles and Lutris) of the current user.
+func defaultDiscoverers() []Discoverer {
+	discoverers := []Discoverer{
+		userConfigDiscoverer{fsys: hostFS{}, installs: catalog.Installs},
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		prefixes := findWinePrefixes(hostFS{}, fsName(home), os.Getenv)
+		discoverers = append(discoverers, wineDiscoverer{fsys: hostFS{}, prefixes: prefixes})
+	}
+	return discoverers
+}
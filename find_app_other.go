@@ -0,0 +1,10 @@
+//go:build !windows && !darwin && !linux
+
+package main
+
+// defaultDiscoverers is empty on systems InDesign does not run on, so
+// findAllInstalledVersions reports the OS as unsupported. The parsers and
+// commands stay buildable (and testable) there.
+func defaultDiscoverers() []Discoverer {
+	return nil
+}
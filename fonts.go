@@ -0,0 +1,328 @@
+package main
+
+import (
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+	"unicode/utf16"
+)
+
+// documentFontsFolder is the folder InDesign's Package command creates next
+// to a document; fonts in it are activated for that document only.
+const documentFontsFolder = "Document fonts"
+
+// fontExtensions are the font files we index.
+var fontExtensions = map[string]bool{
+	".otf": true, ".ttf": true, ".ttc": true, ".otc": true, ".dfont": true,
+	".pfb": true, ".pfm": true,
+}
+
+// fontDirectories returns the standard font folders for this OS.
+// Folders that do not exist are skipped when indexing.
+func fontDirectories() []string {
+	home, _ := os.UserHomeDir()
+
+	switch runtime.GOOS {
+	case "windows":
+		windir := os.Getenv("WINDIR")
+		if windir == "" {
+			windir = `C:\Windows`
+		}
+		dirs := []string{
+			filepath.Join(windir, "Fonts"),
+			`C:\Program Files\Common Files\Adobe\Fonts`,
+		}
+		// Per-user installs (Windows 10 1809 and later).
+		if local := os.Getenv("LOCALAPPDATA"); local != "" {
+			dirs = append(dirs, filepath.Join(local, "Microsoft", "Windows", "Fonts"))
+		}
+		return dirs
+	case "darwin":
+		return []string{
+			"/System/Library/Fonts",
+			"/Library/Fonts",
+			"/Network/Library/Fonts",
+			"/Library/Application Support/Adobe/Fonts",
+			filepath.Join(home, "Library", "Fonts"),
+		}
+	default:
+		return []string{
+			"/usr/share/fonts",
+			"/usr/local/share/fonts",
+			filepath.Join(home, ".fonts"),
+			filepath.Join(home, ".local", "share", "fonts"),
+		}
+	}
+}
+
+// fontIndex maps normalized font names and file names to where they were found.
+type fontIndex map[string]string
+
+// buildFontIndex indexes every font file in the standard font folders and in
+// the "Document fonts" folder next to docPath.
+func buildFontIndex(docPath string) fontIndex {
+	idx := make(fontIndex)
+	for _, dir := range fontDirectories() {
+		idx.addDir(dir, "installed")
+	}
+	idx.addDir(filepath.Join(filepath.Dir(docPath), documentFontsFolder), "document fonts")
+	return idx
+}
+
+// addDir walks dir and records each font under its file name and the
+// PostScript / full names stored in its name table.
+func (idx fontIndex) addDir(dir, source string) {
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !fontExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		idx.add(d.Name(), source)
+		idx.add(strings.TrimSuffix(d.Name(), filepath.Ext(d.Name())), source)
+		for _, name := range readFontNames(path) {
+			idx.add(name, source)
+		}
+		return nil
+	})
+}
+
+func (idx fontIndex) add(name, source string) {
+	key := normalizeFontName(name)
+	if _, ok := idx[key]; !ok && key != "" {
+		idx[key] = source
+	}
+}
+
+// lookup returns where a document font was found, or "" if it is missing.
+func (idx fontIndex) lookup(f FontInfo) string {
+	candidates := []string{f.Name, f.FileName, f.Family + " " + f.Face}
+	for _, c := range candidates {
+		if source, ok := idx[normalizeFontName(c)]; ok {
+			return source
+		}
+	}
+	return ""
+}
+
+// missingFonts returns the document fonts that are not in the index.
+func (idx fontIndex) missingFonts(fonts []FontInfo) []FontInfo {
+	var missing []FontInfo
+	for _, f := range fonts {
+		if idx.lookup(f) == "" {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
+
+// normalizeFontName lowercases a name and drops spaces, dashes and
+// underscores, so "Minion Pro Regular" matches "MinionPro-Regular".
+func normalizeFontName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' || r == '_' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, strings.TrimSpace(name))
+}
+
+// --- sfnt name table ---
+
+// sfnt name IDs we index.
+const (
+	nameIDFullName   = 4
+	nameIDPostScript = 6
+)
+
+// readFontNames returns the full and PostScript names of every font in an
+// OpenType/TrueType file or collection. Unreadable files yield no names.
+func readFontNames(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var tag [4]byte
+	if _, err := f.ReadAt(tag[:], 0); err != nil {
+		return nil
+	}
+
+	// A collection starts with "ttcf" and a list of font offsets.
+	offsets := []int64{0}
+	if string(tag[:]) == "ttcf" {
+		var hdr [12]byte
+		if _, err := f.ReadAt(hdr[:], 0); err != nil {
+			return nil
+		}
+		count := binary.BigEndian.Uint32(hdr[8:12])
+		if count > 256 {
+			return nil
+		}
+		raw := make([]byte, 4*count)
+		if _, err := f.ReadAt(raw, 12); err != nil {
+			return nil
+		}
+		offsets = offsets[:0]
+		for i := uint32(0); i < count; i++ {
+			offsets = append(offsets, int64(binary.BigEndian.Uint32(raw[4*i:])))
+		}
+	}
+
+	var names []string
+	for _, off := range offsets {
+		names = append(names, readSfntNames(f, off)...)
+	}
+	return names
+}
+
+// readSfntNames reads the name table of the font whose offset table is at off.
+func readSfntNames(r io.ReaderAt, off int64) []string {
+	var hdr [12]byte
+	if _, err := r.ReadAt(hdr[:], off); err != nil {
+		return nil
+	}
+	numTables := int(binary.BigEndian.Uint16(hdr[4:6]))
+	records := make([]byte, 16*numTables)
+	if _, err := r.ReadAt(records, off+12); err != nil {
+		return nil
+	}
+
+	for i := 0; i < numTables; i++ {
+		rec := records[16*i:]
+		if string(rec[0:4]) != "name" {
+			continue
+		}
+		tableOff := int64(binary.BigEndian.Uint32(rec[8:12]))
+		tableLen := binary.BigEndian.Uint32(rec[12:16])
+		if tableLen > 1<<20 {
+			return nil
+		}
+		table := make([]byte, tableLen)
+		if _, err := r.ReadAt(table, tableOff); err != nil {
+			return nil
+		}
+		return parseNameTable(table)
+	}
+	return nil
+}
+
+// parseNameTable extracts the full and PostScript name records.
+func parseNameTable(table []byte) []string {
+	if len(table) < 6 {
+		return nil
+	}
+	count := int(binary.BigEndian.Uint16(table[2:4]))
+	storage := int(binary.BigEndian.Uint16(table[4:6]))
+
+	var names []string
+	for i := 0; i < count; i++ {
+		rec := table[6+12*i:]
+		if len(rec) < 12 {
+			break
+		}
+		platform := binary.BigEndian.Uint16(rec[0:2])
+		nameID := binary.BigEndian.Uint16(rec[6:8])
+		length := int(binary.BigEndian.Uint16(rec[8:10]))
+		start := storage + int(binary.BigEndian.Uint16(rec[10:12]))
+		if nameID != nameIDFullName && nameID != nameIDPostScript {
+			continue
+		}
+		if start+length > len(table) {
+			continue
+		}
+		raw := table[start : start+length]
+
+		// Unicode (0) and Windows (3) names are UTF-16BE; Mac (1) names are
+		// single-byte and ASCII for the names we care about.
+		if platform == 0 || platform == 3 {
+			u := make([]uint16, len(raw)/2)
+			for j := range u {
+				u[j] = binary.BigEndian.Uint16(raw[2*j:])
+			}
+			names = append(names, string(utf16.Decode(u)))
+		} else {
+			names = append(names, string(raw))
+		}
+	}
+	return names
+}
+
+// --- fonts command ---
+
+// runFonts implements the "fonts" command: it lists the fonts recorded in
+// the document's XMP and checks each one against the local fonts.
+func runFonts(args []string) error {
+	fs := flag.NewFlagSet("fonts", flag.ExitOnError)
+	checkFlag := fs.Bool("check", true, "Check each font against the locally installed fonts")
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: %s", commands["fonts"].usage)
+	}
+	filePath := positional[0]
+
+	md, err := getXMPMetadata(filePath)
+	if err != nil {
+		return fmt.Errorf("error reading XMP from '%s': %w", filePath, err)
+	}
+
+	fmt.Printf("File: %s\n", filePath)
+	if len(md.Fonts) == 0 {
+		fmt.Println("No fonts recorded.")
+		return nil
+	}
+
+	var idx fontIndex
+	if *checkFlag {
+		idx = buildFontIndex(filePath)
+	}
+
+	missing := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tFAMILY\tFACE\tTYPE\tVERSION\tSTATUS")
+	for _, f := range md.Fonts {
+		status := "-"
+		if idx != nil {
+			status = idx.lookup(f)
+			if status == "" {
+				status = "MISSING"
+				missing++
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", f.Name, f.Family, f.Face, f.Type, f.Version, status)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if idx != nil {
+		fmt.Printf("%d font(s), %d missing\n", len(md.Fonts), missing)
+	}
+	return nil
+}
+
+// fontsWarning returns a launch warning listing the document's missing
+// fonts, or "" when every font is available.
+func fontsWarning(md *XMPMetadata, docPath string) string {
+	if md == nil || len(md.Fonts) == 0 {
+		return ""
+	}
+	missing := buildFontIndex(docPath).missingFonts(md.Fonts)
+	if len(missing) == 0 {
+		return ""
+	}
+	names := make([]string, len(missing))
+	for i, f := range missing {
+		names[i] = strings.TrimSpace(f.Family + " " + f.Face)
+	}
+	return fmt.Sprintf("%d missing font(s): %s", len(missing), strings.Join(names, ", "))
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"id-launcher/internal/fixture"
+)
+
+// minionNames are the name records of a typical OpenType font.
+var minionNames = []fixture.FontName{
+	{Platform: fixture.PlatformMac, ID: 1, Value: "Minion Pro"},
+	{Platform: fixture.PlatformMac, ID: 4, Value: "Minion Pro Regular"},
+	{Platform: fixture.PlatformWindows, ID: 4, Value: "Minion Pro Regular"},
+	{Platform: fixture.PlatformWindows, ID: 6, Value: "MinionPro-Regular"},
+}
+
+func TestParseNameTable(t *testing.T) {
+	full := fixture.NameTable(minionNames...)
+	unicode := fixture.NameTable(fixture.FontName{Platform: fixture.PlatformUnicode, ID: 6, Value: "Ärger-Bold"})
+	// The string of the second record points past the end of the table.
+	broken := fixture.NameTable(
+		fixture.FontName{Platform: fixture.PlatformMac, ID: 4, Value: "Kept"},
+		fixture.FontName{Platform: fixture.PlatformMac, ID: 6, Value: "Lost"},
+	)
+	broken = broken[:len(broken)-2]
+
+	tests := []struct {
+		name  string
+		table []byte
+		want  []string
+	}{
+		{"mac and windows", full, []string{"Minion Pro Regular", "Minion Pro Regular", "MinionPro-Regular"}},
+		{"unicode platform", unicode, []string{"Ärger-Bold"}},
+		{"string out of range", broken, []string{"Kept"}},
+		{"records cut off", full[:20], nil},
+		{"too short", full[:4], nil},
+		{"empty", nil, nil},
+	}
+	for _, tt := range tests {
+		if got := parseNameTable(tt.table); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadFontNames(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	font := write("MinionPro-Regular.otf", fixture.Font(minionNames...))
+	collection := write("Helvetica.ttc", fixture.Collection(
+		[]fixture.FontName{{Platform: fixture.PlatformWindows, ID: 6, Value: "Helvetica"}},
+		[]fixture.FontName{{Platform: fixture.PlatformWindows, ID: 6, Value: "Helvetica-Bold"}},
+	))
+	garbage := write("Broken.ttf", []byte("not a font at all"))
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{font, []string{"Minion Pro Regular", "Minion Pro Regular", "MinionPro-Regular"}},
+		{collection, []string{"Helvetica", "Helvetica-Bold"}},
+		{garbage, nil},
+		{filepath.Join(dir, "missing.otf"), nil},
+	}
+	for _, tt := range tests {
+		if got := readFontNames(tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", filepath.Base(tt.path), got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeFontName(t *testing.T) {
+	for name, want := range map[string]string{
+		"MinionPro-Regular":    "minionproregular",
+		" Minion Pro Regular ": "minionproregular",
+		"Helvetica_Neue\tBold": "helveticaneuebold",
+		"":                     "",
+	} {
+		if got := normalizeFontName(name); got != want {
+			t.Errorf("normalizeFontName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestFontIndexLookup(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "MinionPro-Regular.otf"), fixture.Font(minionNames...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Renamed.otf"), fixture.Font(fixture.FontName{Platform: fixture.PlatformWindows, ID: 6, Value: "Garamond-Italic"}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.txt"), []byte("Futura"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	idx := make(fontIndex)
+	idx.addDir(dir, "installed")
+	idx.addDir(filepath.Join(dir, "missing"), "document fonts")
+
+	tests := []struct {
+		name string
+		font FontInfo
+		want string
+	}{
+		{"PostScript name", FontInfo{Name: "MinionPro-Regular"}, "installed"},
+		{"family and face", FontInfo{Name: "X", Family: "Minion Pro", Face: "Regular"}, "installed"},
+		{"file name", FontInfo{Name: "X", FileName: "Renamed.otf"}, "installed"},
+		{"name table only", FontInfo{Name: "Garamond-Italic"}, "installed"},
+		{"not a font file", FontInfo{Name: "Futura", Family: "Futura", Face: "Book"}, ""},
+	}
+	for _, tt := range tests {
+		if got := idx.lookup(tt.font); got != tt.want {
+			t.Errorf("%s: lookup = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if missing := idx.missingFonts([]FontInfo{tests[0].font, tests[4].font}); len(missing) != 1 || missing[0].Name != "Futura" {
+		t.Errorf("missingFonts = %v, want only Futura", missing)
+	}
+}
+
+func FuzzParseNameTable(f *testing.F) {
+	f.Add(fixture.NameTable(minionNames...))
+	f.Add(fixture.NameTable())
+	f.Fuzz(func(t *testing.T, table []byte) {
+		// Must not panic.
+		parseNameTable(table)
+	})
+}
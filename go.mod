@@ -3,6 +3,7 @@ module id-launcher
 go 1.25.4
 
 require (
+	github.com/gen2brain/beeep v0.11.1
 	github.com/tc-hib/winres v0.3.1
 	golang.org/x/sys v0.38.0
 )
@@ -10,7 +11,6 @@ require (
 require (
 	git.sr.ht/~jackmordaunt/go-toast v1.1.2 // indirect
 	github.com/esiqveland/notify v0.13.3 // indirect
-	github.com/gen2brain/beeep v0.11.1 // indirect
 	github.com/go-ole/go-ole v1.3.0 // indirect
 	github.com/godbus/dbus/v5 v5.2.0 // indirect
 	github.com/jackmordaunt/icns/v3 v3.0.1 // indirect
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// SoftwareAgent is a decoded XMP softwareAgent / CreatorTool value,
+// e.g. "Adobe InDesign 19.2 (Macintosh)".
+type SoftwareAgent struct {
+	// Product is the Adobe application, e.g. "InDesign" or "InCopy".
+	Product string
+	// Build is the version exactly as written, e.g. "19.2" or "CS6".
+	Build string
+	// Major and Minor are the numeric version; Minor is 0 for named builds.
+	Major uint32
+	Minor uint32
+	// Platform is the operating system, e.g. "Macintosh" or "Windows".
+	Platform string
+}
+
+// String formats the agent the way InDesign writes it.
+func (a SoftwareAgent) String() string {
+	s := fmt.Sprintf("Adobe %s %s", a.Product, a.Build)
+	if a.Platform != "" {
+		s += fmt.Sprintf(" (%s)", a.Platform)
+	}
+	return s
+}
+
+// softwareAgentPattern matches "Adobe <Product> [Server] <build> [(<platform>)]".
+var softwareAgentPattern = regexp.MustCompile(`^Adobe (InDesign|InCopy)(?: Server)? (.+?)(?: \(([^)]*)\))?$`)
+
+// numericBuildPattern matches builds such as "19.2", "19.2.1" or "CC 14.0".
+var numericBuildPattern = regexp.MustCompile(`^(?:CC )?(\d+)(?:\.(\d+))?`)
+
+// parseSoftwareAgent decodes a softwareAgent string. It returns false for
+// anything that is not an InDesign or InCopy agent with a known version.
+func parseSoftwareAgent(s string) (SoftwareAgent, bool) {
+	m := softwareAgentPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return SoftwareAgent{}, false
+	}
+	agent := SoftwareAgent{Product: m[1], Build: m[2], Platform: m[3]}
+
+	// Named builds ("CS6", "CC 2014") and plain numeric ones ("19.2")
+	// parse as versions; a few builds carry extra text and fall through.
+	if v, err := parseVersion(agent.Build); err == nil {
+		agent.Major, agent.Minor = v.Major, v.Minor
+		return agent, true
+	}
+	n := numericBuildPattern.FindStringSubmatch(agent.Build)
+	if n == nil {
+		return SoftwareAgent{}, false
+	}
+	major, err := strconv.ParseUint(n[1], 10, 32)
+	if err != nil {
+		return SoftwareAgent{}, false
+	}
+	agent.Major = uint32(major)
+	if n[2] != "" {
+		if minor, err := strconv.ParseUint(n[2], 10, 32); err == nil {
+			agent.Minor = uint32(minor)
+		}
+	}
+	return agent, true
+}
+
+// lastSaveAgent returns the software agent of the most recent history
+// event that names a recognizable InDesign or InCopy build.
+func (md *XMPMetadata) lastSaveAgent() (SoftwareAgent, bool) {
+	for i := len(md.History) - 1; i >= 0; i-- {
+		if agent, ok := parseSoftwareAgent(md.History[i].SoftwareAgent); ok {
+			return agent, true
+		}
+	}
+	return SoftwareAgent{}, false
+}
+
+// runHistory implements the "history" command: it lists every save event
+// recorded in the file's XMP with the exact build and platform.
+func runHistory(args []string) error {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: %s", commands["history"].usage)
+	}
+	filePath := positional[0]
+
+	md, err := getXMPMetadata(filePath)
+	if err != nil {
+		return fmt.Errorf("error reading XMP from '%s': %w", filePath, err)
+	}
+
+	fmt.Printf("File: %s\n", filePath)
+	if md.CreatorTool != "" {
+		fmt.Printf("Created with: %s\n", md.CreatorTool)
+	}
+	if len(md.History) == 0 {
+		fmt.Println("No save history recorded.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tWHEN\tACTION\tBUILD\tPLATFORM\tCHANGED")
+	for i, event := range md.History {
+		when := ""
+		if !event.When.IsZero() {
+			when = event.When.Format("2006-01-02 15:04:05 -0700")
+		}
+		build, platform := event.SoftwareAgent, ""
+		if agent, ok := parseSoftwareAgent(event.SoftwareAgent); ok {
+			build = fmt.Sprintf("%s %s", agent.Product, agent.Build)
+			platform = agent.Platform
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", i+1, when, event.Action, build, platform, event.Changed)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// An IDML file is a ZIP package. Its designmap.xml starts with the <?aid?>
+// instruction that records the InDesign version which exported it.
+
+// idmlDesignMap is the package entry holding the document's structure.
+const idmlDesignMap = "designmap.xml"
+
+// zipMagic is the signature at the start of every ZIP local file header.
+var zipMagic = []byte("PK\x03\x04")
+
+// isIDMLFile reports whether filePath looks like an IDML package, by
+// extension or, for renamed files, by its ZIP signature.
+func isIDMLFile(filePath string) bool {
+	if strings.EqualFold(filepath.Ext(filePath), ".idml") {
+		return true
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	var sig [4]byte
+	if _, err := f.Read(sig[:]); err != nil {
+		return false
+	}
+	return bytes.Equal(sig[:], zipMagic)
+}
+
+// getIDMLVersion opens an IDML package and returns the <?aid?> instruction
+// from its designmap.xml.
+func getIDMLVersion(filePath string) (AIDInstruction, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return AIDInstruction{}, fmt.Errorf("could not open file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return AIDInstruction{}, fmt.Errorf("could not stat file: %w", err)
+	}
+	return getIDMLVersionAt(f, info.Size())
+}
+
+// getIDMLVersionAt reads the <?aid?> instruction of the size-byte IDML
+// package read from r.
+func getIDMLVersionAt(r io.ReaderAt, size int64) (AIDInstruction, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return AIDInstruction{}, fmt.Errorf("not a valid IDML package: %w", err)
+	}
+
+	f, err := zr.Open(idmlDesignMap)
+	if err != nil {
+		return AIDInstruction{}, fmt.Errorf("not a valid IDML package (missing %s): %w", idmlDesignMap, err)
+	}
+	defer f.Close()
+
+	return readAIDInstruction(f)
+}
+
+// getIDMLVersionFrom reads the <?aid?> instruction of an IDML package read
+// as a stream. A ZIP's directory sits at its end, so the whole stream is
+// read.
+func getIDMLVersionFrom(r io.Reader) (AIDInstruction, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return AIDInstruction{}, fmt.Errorf("could not read file: %w", err)
+	}
+	return getIDMLVersionAt(bytes.NewReader(data), int64(len(data)))
+}
+
+// getIDMLVersionFS reads the <?aid?> instruction of the named IDML package
+// in fsys.
+func getIDMLVersionFS(fsys fs.FS, name string) (AIDInstruction, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return AIDInstruction{}, fmt.Errorf("could not open file: %w", err)
+	}
+	defer f.Close()
+
+	if ra, ok := f.(io.ReaderAt); ok {
+		if info, err := f.Stat(); err == nil {
+			return getIDMLVersionAt(ra, info.Size())
+		}
+	}
+	return getIDMLVersionFrom(f)
+}
+
+// describeIDMLFile reads an IDML package's version and prints it. IDML is
+// forward-compatible, so the newest installed version is used.
+func describeIDMLFile(absPath string) (launchTarget, error) {
+	aid, err := getIDMLVersion(absPath)
+	if err != nil {
+		return launchTarget{}, err
+	}
+	fmt.Printf("Detected File Kind: IDML package\n")
+	version := Version{Major: aid.Major, Minor: aid.Minor}
+	fmt.Printf("Detected File Version: %s (Major: %d, Minor: %d, Product: %s)\n", version.Name(), aid.Major, aid.Minor, aid.Product)
+	return launchTarget{version: version, policy: policyNewest}, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pointsPerUnit converts stDim units to PostScript points. XMP specifies
+// "inch", "mm", "pixel", "pica" and "point"; InDesign writes the plural
+// English names ("Millimeters", "Inches", ...), so both are accepted.
+var pointsPerUnit = map[string]float64{
+	"point": 1, "points": 1, "pt": 1,
+	"pixel": 1, "pixels": 1, "px": 1, // InDesign pixels are 1/72 inch
+	"pica": 12, "picas": 12, "pc": 12,
+	"inch": 72, "inches": 72, "in": 72,
+	"mm": 72 / 25.4, "millimeter": 72 / 25.4, "millimeters": 72 / 25.4, "millimetre": 72 / 25.4, "millimetres": 72 / 25.4,
+	"cm": 72 / 2.54, "centimeter": 72 / 2.54, "centimeters": 72 / 2.54, "centimetre": 72 / 2.54, "centimetres": 72 / 2.54,
+}
+
+// inPoints returns the page size in points, or false if the size is
+// missing or its unit is unknown.
+func (s PageSize) inPoints() (w, h float64, ok bool) {
+	factor, ok := pointsPerUnit[strings.ToLower(strings.TrimSpace(s.Unit))]
+	if !ok || s.Width <= 0 || s.Height <= 0 {
+		return 0, 0, false
+	}
+	return s.Width * factor, s.Height * factor, true
+}
+
+// String formats the page size in millimeters, inches and points, e.g.
+// "210 x 297 mm (8.27 x 11.69 in, 595.28 x 841.89 pt)".
+func (s PageSize) String() string {
+	w, h, ok := s.inPoints()
+	if !ok {
+		if s.Unit == "" {
+			return "unknown"
+		}
+		return fmt.Sprintf("%s x %s %s", formatLength(s.Width), formatLength(s.Height), s.Unit)
+	}
+	return fmt.Sprintf("%s x %s mm (%s x %s in, %s x %s pt)",
+		formatLength(w/pointsPerUnit["mm"]), formatLength(h/pointsPerUnit["mm"]),
+		formatLength(w/72), formatLength(h/72),
+		formatLength(w), formatLength(h))
+}
+
+// formatLength rounds to two decimals and drops trailing zeros.
+func formatLength(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// runInfo implements the "info" command: a one-screen summary of a
+// document with its version, page count and page size, for triaging files
+// without opening them.
+func runInfo(args []string) error {
+	fs := flag.NewFlagSet("info", flag.ExitOnError)
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: %s", commands["info"].usage)
+	}
+	filePath := positional[0]
+
+	header, err := getInDesignVersion(filePath)
+	if err != nil {
+		return fmt.Errorf("error reading file '%s': %w", filePath, err)
+	}
+
+	fmt.Printf("File: %s\n", filePath)
+	fmt.Printf("Kind: %s\n", resolveKind(header.Kind, filePath))
+	fmt.Printf("Version: %s (Major: %d, Minor: %d)\n", header.Version().Name(), header.MajorVersion, header.MinorVersion)
+
+	// Page geometry only exists in the XMP packet.
+	md, err := getXMPMetadata(filePath)
+	if err != nil {
+		fmt.Printf("Pages: unknown (%v)\n", err)
+		return nil
+	}
+	if md.NPages > 0 {
+		fmt.Printf("Pages: %d\n", md.NPages)
+	} else {
+		fmt.Println("Pages: unknown")
+	}
+	fmt.Printf("Page size: %s\n", md.MaxPageSize)
+	if md.Title != "" {
+		fmt.Printf("Title: %s\n", md.Title)
+	}
+	if !md.ModifyDate.IsZero() {
+		fmt.Printf("Last modified: %s\n", md.ModifyDate.Format(time.RFC1123))
+	}
+	return nil
+}
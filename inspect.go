@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// headerField describes one recognized field of a master page, for the
+// annotated views of the "inspect" command.
+type headerField struct {
+	offset int
+	length int
+	name   string
+	// describe explains the field's value; page holds the whole master
+	// page read so far and is at least offset+length bytes long.
+	describe func(page []byte) string
+}
+
+// masterPageFields lists the fields of a master page in file order.
+var masterPageFields = []headerField{
+	{0, 16, "magic number", func(p []byte) string {
+		if bytes.Equal(p[0:16], magicNumber) {
+			return "ok"
+		}
+		return fmt.Sprintf("MISMATCH, expected % x", magicNumber)
+	}},
+	{16, 8, "type", func(p []byte) string {
+		t := string(bytes.TrimRight(p[16:24], "\x00"))
+		return fmt.Sprintf("%q = %s", t, kindFromType(t))
+	}},
+	{24, 1, "endianness flag", func(p []byte) string {
+		switch p[24] {
+		case endianLittle:
+			return "1 = little endian"
+		case endianBig:
+			return "2 = big endian"
+		default:
+			return fmt.Sprintf("%d = UNKNOWN (want 1 or 2)", p[24])
+		}
+	}},
+	{25, 4, "padding", func(p []byte) string { return "" }},
+	{29, 4, "major version", func(p []byte) string {
+		major := inspectByteOrder(p).Uint32(p[29:33])
+		if major == 0 || major > maxPlausibleMajor {
+			return fmt.Sprintf("%d = IMPLAUSIBLE", major)
+		}
+		return fmt.Sprintf("%d = %s", major, Version{Major: major}.Name())
+	}},
+	{33, 4, "minor version", func(p []byte) string {
+		return fmt.Sprint(inspectByteOrder(p).Uint32(p[33:37]))
+	}},
+	{sequenceOffset, 8, "commit sequence", func(p []byte) string {
+		return fmt.Sprint(binary.LittleEndian.Uint64(p[sequenceOffset : sequenceOffset+8]))
+	}},
+	{pageCountOffset, 4, "page count", func(p []byte) string {
+		return fmt.Sprint(binary.LittleEndian.Uint32(p[pageCountOffset : pageCountOffset+4]))
+	}},
+}
+
+// inspectByteOrder returns the byte order selected by the flag at byte 24,
+// defaulting to little endian so damaged pages can still be displayed.
+func inspectByteOrder(page []byte) binary.ByteOrder {
+	if page[24] == endianBig {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
+
+// runInspect implements the "inspect" command: it shows every recognized
+// header field of both master pages, optionally as an annotated hex dump,
+// and explains why parsing fails for broken files.
+func runInspect(args []string) error {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	hexFlag := fs.Bool("hex", false, "Print an annotated hex dump of each master page")
+	bytesFlag := fs.Int("bytes", masterFieldsSize, "Number of bytes of each master page to dump with -hex")
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: %s", commands["inspect"].usage)
+	}
+	filePath := positional[0]
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("could not open file: %w", err)
+	}
+	defer f.Close()
+
+	data := make([]byte, masterPageCount*pageSize)
+	n, err := io.ReadFull(f, data)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return fmt.Errorf("could not read file: %w", err)
+	}
+	data = data[:n]
+
+	fmt.Printf("File: %s (%d bytes read)\n", filePath, n)
+	for i := 0; i < masterPageCount; i++ {
+		base := i * pageSize
+		if base >= len(data) {
+			fmt.Printf("\nMaster page %d: missing (file ends at byte %d)\n", i, len(data))
+			continue
+		}
+		page := data[base:min(len(data), base+pageSize)]
+
+		fmt.Printf("\nMaster page %d (bytes %d-%d)\n", i, base, base+pageSize-1)
+		if *hexFlag {
+			printAnnotatedHex(os.Stdout, page, base, min(len(page), max(*bytesFlag, 0)))
+		} else {
+			printFields(os.Stdout, page, base)
+		}
+	}
+
+	// Finish with the parser's own verdict.
+	fmt.Println()
+	pages, err := readMasterPages(bytes.NewReader(data))
+	if err != nil {
+		fmt.Printf("Result: INVALID: %v\n", err)
+		return nil
+	}
+	active := activeMasterPage(pages)
+	h := pages[active].Header
+	fmt.Printf("Result: valid %s, active master page %d, version %s (Major: %d, Minor: %d)\n",
+		h.Kind, pages[active].Index, h.Version().Name(), h.MajorVersion, h.MinorVersion)
+	return nil
+}
+
+// printFields lists every recognized field present in page.
+func printFields(w io.Writer, page []byte, base int) {
+	for _, field := range masterPageFields {
+		if field.offset+field.length > len(page) {
+			fmt.Fprintf(w, "  %6d  %-16s (missing)\n", base+field.offset, field.name)
+			continue
+		}
+		fmt.Fprintf(w, "  %6d  %-16s % x  %s\n", base+field.offset, field.name,
+			page[field.offset:field.offset+field.length], field.describe(page))
+	}
+}
+
+// printAnnotatedHex dumps the first n bytes of page, 16 per row, and
+// annotates each row with the fields that start in it. Runs of all-zero
+// rows without fields are collapsed into a single "*".
+func printAnnotatedHex(w io.Writer, page []byte, base, n int) {
+	const rowSize = 16
+	collapsed := false
+	for row := 0; row < n; row += rowSize {
+		line := page[row:min(n, row+rowSize)]
+
+		var notes []string
+		for _, field := range masterPageFields {
+			if field.offset < row || field.offset >= row+rowSize || field.offset >= n {
+				continue
+			}
+			note := fmt.Sprintf("[%d-%d] %s", base+field.offset, base+field.offset+field.length-1, field.name)
+			if field.offset+field.length <= len(page) {
+				if d := field.describe(page); d != "" {
+					note += ": " + d
+				}
+			} else {
+				note += ": (truncated)"
+			}
+			notes = append(notes, note)
+		}
+
+		if len(notes) == 0 && len(bytes.Trim(line, "\x00")) == 0 {
+			if !collapsed {
+				fmt.Fprintln(w, "*")
+				collapsed = true
+			}
+			continue
+		}
+		collapsed = false
+
+		hexPart, asciiPart := formatHexRow(line, rowSize)
+		prefix := fmt.Sprintf("%08x  %s |%s|", base+row, hexPart, asciiPart)
+		if len(notes) == 0 {
+			fmt.Fprintln(w, prefix)
+			continue
+		}
+		fmt.Fprintf(w, "%s  <- %s\n", prefix, notes[0])
+		for _, note := range notes[1:] {
+			fmt.Fprintf(w, "%s  <- %s\n", strings.Repeat(" ", len(prefix)), note)
+		}
+	}
+}
+
+// formatHexRow formats one row in the classic "hexdump -C" layout.
+func formatHexRow(line []byte, rowSize int) (string, string) {
+	var hexPart, asciiPart strings.Builder
+	for i := 0; i < rowSize; i++ {
+		if i == rowSize/2 {
+			hexPart.WriteByte(' ')
+		}
+		if i >= len(line) {
+			// Pad short rows so the annotations stay aligned.
+			hexPart.WriteString("   ")
+			asciiPart.WriteByte(' ')
+			continue
+		}
+		fmt.Fprintf(&hexPart, "%02x ", line[i])
+		if line[i] >= 0x20 && line[i] < 0x7f {
+			asciiPart.WriteByte(line[i])
+		} else {
+			asciiPart.WriteByte('.')
+		}
+	}
+	return hexPart.String(), asciiPart.String()
+}
@@ -0,0 +1,140 @@
+// Package fixture generates minimal synthetic InDesign files for tests.
+//
+// Real documents are large and proprietary, so tests build the few bytes
+// the launcher actually reads instead: the two database master pages (magic
+// GUID, type, endianness, version, commit sequence, page count) and,
+// optionally, an XMP packet after the database pages. Everything else is
+// zero. The files are "valid-looking" only; InDesign itself will not open
+// them.
+package fixture
+
+import (
+	"encoding/binary"
+	"os"
+)
+
+// Layout of an InDesign database, as read by the launcher.
+const (
+	// PageSize is the size of a database page.
+	PageSize = 4096
+	// MasterPageCount is the number of master pages at the start of the file.
+	MasterPageCount = 2
+
+	offsetType      = 16
+	offsetEndian    = 24
+	offsetMajor     = 29
+	offsetMinor     = 33
+	offsetSequence  = 264
+	offsetPageCount = 280
+)
+
+// Endianness flag values (byte 24).
+const (
+	LittleEndian byte = 1
+	BigEndian    byte = 2
+)
+
+// Type field values (bytes 16-23).
+const (
+	TypeDocument = "DOCUMENT"
+	TypeBook     = "BOOKBOOK"
+	TypeLibrary  = "LIBRARY4"
+)
+
+// Magic is the GUID every InDesign database starts with.
+var Magic = [16]byte{
+	0x06, 0x06, 0xED, 0xF5, 0xD8, 0x1D, 0x46, 0xE5,
+	0xBD, 0x31, 0xEF, 0xE7, 0xFE, 0x74, 0xB7, 0x1D,
+}
+
+// MasterPage holds the fields of one master page.
+type MasterPage struct {
+	// GUID is written to bytes 0-15.
+	GUID [16]byte
+	// Type is written to bytes 16-23, truncated or zero-padded to 8 bytes.
+	Type string
+	// Endian is the raw flag byte; values other than LittleEndian and
+	// BigEndian are written as is, and the versions are then little endian.
+	Endian byte
+	// Major and Minor are the version, in the byte order selected by Endian.
+	Major uint32
+	Minor uint32
+	// Sequence is the commit sequence number; the higher one is active.
+	Sequence uint64
+	// PageCount is the number of database pages; the XMP packet follows
+	// them. Zero means MasterPageCount.
+	PageCount uint32
+}
+
+// File describes a synthetic InDesign file.
+type File struct {
+	// Master holds both master pages, in file order.
+	Master [MasterPageCount]MasterPage
+	// XMP is appended after the database pages when not nil; see Packet.
+	XMP []byte
+}
+
+// New returns a little-endian file of the given type and version whose
+// master pages are identical except for their sequence numbers; the
+// second page is active.
+func New(typ string, major, minor uint32) *File {
+	page := MasterPage{
+		GUID:   Magic,
+		Type:   typ,
+		Endian: LittleEndian,
+		Major:  major,
+		Minor:  minor,
+	}
+	f := &File{Master: [MasterPageCount]MasterPage{page, page}}
+	f.Master[0].Sequence = 1
+	f.Master[1].Sequence = 2
+	return f
+}
+
+// Document returns a little-endian document file of the given version.
+func Document(major, minor uint32) *File {
+	return New(TypeDocument, major, minor)
+}
+
+// Bytes encodes the file.
+func (f *File) Bytes() []byte {
+	pages := uint32(MasterPageCount)
+	for _, m := range f.Master {
+		pages = max(pages, m.PageCount)
+	}
+
+	buf := make([]byte, int(pages)*PageSize, int(pages)*PageSize+len(f.XMP))
+	for i, m := range f.Master {
+		m.encode(buf[i*PageSize : (i+1)*PageSize])
+	}
+	return append(buf, f.XMP...)
+}
+
+// WriteFile writes the encoded file to path.
+func (f *File) WriteFile(path string) error {
+	return os.WriteFile(path, f.Bytes(), 0644)
+}
+
+// encode writes the master page fields into page.
+func (m MasterPage) encode(page []byte) {
+	copy(page[0:16], m.GUID[:])
+
+	var typ [8]byte
+	copy(typ[:], m.Type)
+	copy(page[offsetType:], typ[:])
+
+	page[offsetEndian] = m.Endian
+	var order binary.ByteOrder = binary.LittleEndian
+	if m.Endian == BigEndian {
+		order = binary.BigEndian
+	}
+	order.PutUint32(page[offsetMajor:], m.Major)
+	order.PutUint32(page[offsetMinor:], m.Minor)
+
+	pageCount := m.PageCount
+	if pageCount == 0 {
+		pageCount = MasterPageCount
+	}
+	binary.LittleEndian.PutUint64(page[offsetSequence:], m.Sequence)
+	binary.LittleEndian.PutUint32(page[offsetPageCount:], pageCount)
+}
@@ -0,0 +1,87 @@
+package fixture
+
+import (
+	"encoding/binary"
+	"unicode/utf16"
+)
+
+// Platform IDs of sfnt name records.
+const (
+	PlatformUnicode uint16 = 0
+	PlatformMac     uint16 = 1
+	PlatformWindows uint16 = 3
+)
+
+// FontName is one record of an sfnt "name" table.
+type FontName struct {
+	// Platform is PlatformUnicode, PlatformMac or PlatformWindows. Unicode
+	// and Windows names are written as UTF-16BE, Mac names as bytes.
+	Platform uint16
+	// ID is the name ID, e.g. 1 (family), 4 (full name) or 6 (PostScript).
+	ID    uint16
+	Value string
+}
+
+// NameTable encodes a format 0 "name" table with the given records.
+func NameTable(names ...FontName) []byte {
+	var storage []byte
+	table := binary.BigEndian.AppendUint16(nil, 0)
+	table = binary.BigEndian.AppendUint16(table, uint16(len(names)))
+	table = binary.BigEndian.AppendUint16(table, uint16(6+12*len(names)))
+	for _, n := range names {
+		var raw []byte
+		if n.Platform == PlatformMac {
+			raw = []byte(n.Value)
+		} else {
+			for _, u := range utf16.Encode([]rune(n.Value)) {
+				raw = binary.BigEndian.AppendUint16(raw, u)
+			}
+		}
+		encoding := uint16(1)
+		if n.Platform == PlatformMac {
+			encoding = 0
+		}
+		for _, v := range []uint16{n.Platform, encoding, 0, n.ID, uint16(len(raw)), uint16(len(storage))} {
+			table = binary.BigEndian.AppendUint16(table, v)
+		}
+		storage = append(storage, raw...)
+	}
+	return append(table, storage...)
+}
+
+// Font encodes a minimal OpenType font whose only table is a name table
+// with the given records.
+func Font(names ...FontName) []byte {
+	return font(0, names)
+}
+
+// Collection encodes a "ttcf" font collection with one font per list of
+// name records.
+func Collection(fonts ...[]FontName) []byte {
+	out := []byte("ttcf")
+	out = binary.BigEndian.AppendUint32(out, 0x00010000)
+	out = binary.BigEndian.AppendUint32(out, uint32(len(fonts)))
+	headerEnd := len(out) + 4*len(fonts)
+	var body []byte
+	for _, names := range fonts {
+		off := headerEnd + len(body)
+		out = binary.BigEndian.AppendUint32(out, uint32(off))
+		body = append(body, font(off, names)...)
+	}
+	return append(out, body...)
+}
+
+// font encodes a font that starts at base in its file, since table offsets
+// count from the start of the file.
+func font(base int, names []FontName) []byte {
+	table := NameTable(names...)
+	out := binary.BigEndian.AppendUint32(nil, 0x4F54544F) // "OTTO"
+	for _, v := range []uint16{1, 16, 0, 0} {             // numTables, searchRange, entrySelector, rangeShift
+		out = binary.BigEndian.AppendUint16(out, v)
+	}
+	out = append(out, "name"...)
+	out = binary.BigEndian.AppendUint32(out, 0) // checksum, not verified
+	out = binary.BigEndian.AppendUint32(out, uint32(base+12+16))
+	out = binary.BigEndian.AppendUint32(out, uint32(len(table)))
+	return append(out, table...)
+}
@@ -0,0 +1,113 @@
+package fixture
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"unicode/utf16"
+)
+
+// BinaryPlist encodes v as a "bplist00" property list, the binary format
+// of macOS Info.plist files. v may be a map[string]any, []any, string,
+// int, int64, float64, bool or []byte; dictionary keys are written in
+// sorted order. Every value becomes its own object, so nothing is shared.
+func BinaryPlist(v any) []byte {
+	var objects [][]byte
+	var add func(v any) int
+	add = func(v any) int {
+		i := len(objects)
+		objects = append(objects, nil)
+		var b bytes.Buffer
+		switch v := v.(type) {
+		case bool:
+			if v {
+				b.WriteByte(0x09)
+			} else {
+				b.WriteByte(0x08)
+			}
+		case int:
+			b.Write(plistInt(int64(v)))
+		case int64:
+			b.Write(plistInt(v))
+		case float64:
+			b.WriteByte(0x23)
+			binary.Write(&b, binary.BigEndian, math.Float64bits(v))
+		case []byte:
+			b.Write(plistLength(0x40, len(v)))
+			b.Write(v)
+		case string:
+			ascii := true
+			for _, r := range v {
+				if r > 0x7f {
+					ascii = false
+				}
+			}
+			if ascii {
+				b.Write(plistLength(0x50, len(v)))
+				b.WriteString(v)
+			} else {
+				u := utf16.Encode([]rune(v))
+				b.Write(plistLength(0x60, len(u)))
+				binary.Write(&b, binary.BigEndian, u)
+			}
+		case []any:
+			refs := make([]uint16, len(v))
+			for j, e := range v {
+				refs[j] = uint16(add(e))
+			}
+			b.Write(plistLength(0xa0, len(v)))
+			binary.Write(&b, binary.BigEndian, refs)
+		case map[string]any:
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			refs := make([]uint16, 2*len(keys))
+			for j, k := range keys {
+				refs[j] = uint16(add(k))
+				refs[len(keys)+j] = uint16(add(v[k]))
+			}
+			b.Write(plistLength(0xd0, len(keys)))
+			binary.Write(&b, binary.BigEndian, refs)
+		default:
+			panic(fmt.Sprintf("fixture: cannot encode %T in a plist", v))
+		}
+		objects[i] = b.Bytes()
+		return i
+	}
+	add(v)
+
+	// Objects, then a table of 4-byte offsets, then the trailer with
+	// 2-byte object references.
+	out := bytes.NewBufferString("bplist00")
+	offsets := make([]uint32, len(objects))
+	for i, o := range objects {
+		offsets[i] = uint32(out.Len())
+		out.Write(o)
+	}
+	tableOffset := out.Len()
+	binary.Write(out, binary.BigEndian, offsets)
+	out.Write(make([]byte, 6))
+	out.Write([]byte{4, 2})
+	binary.Write(out, binary.BigEndian, []uint64{uint64(len(objects)), 0, uint64(tableOffset)})
+	return out.Bytes()
+}
+
+// plistInt encodes an 8-byte integer object.
+func plistInt(n int64) []byte {
+	b := []byte{0x13, 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint64(b[1:], uint64(n))
+	return b
+}
+
+// plistLength encodes a marker with a length: in the marker when it fits,
+// otherwise as an integer object after it.
+func plistLength(marker byte, n int) []byte {
+	if n < 0x0f {
+		return []byte{marker | byte(n)}
+	}
+	return append([]byte{marker | 0x0f}, plistInt(int64(n))...)
+}
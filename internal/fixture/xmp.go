@@ -0,0 +1,96 @@
+package fixture
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+)
+
+// XMP describes the properties written by Packet. Empty fields are left out.
+type XMP struct {
+	// CreatorTool is xmp:CreatorTool, e.g. "Adobe InDesign 19.2 (Windows)".
+	CreatorTool string
+	// CreateDate and ModifyDate are written as is, e.g. "2024-03-05T10:11:12+01:00".
+	CreateDate string
+	ModifyDate string
+	// DocumentID and InstanceID are the xmpMM: identifiers.
+	DocumentID string
+	InstanceID string
+	// Title is the x-default dc:title.
+	Title string
+	// SoftwareAgents become one "saved" xmpMM:History event each, oldest first.
+	SoftwareAgents []string
+	// NPages is xmpTPg:NPages; zero leaves it out.
+	NPages int
+	// PageWidth, PageHeight and PageUnit make up xmpTPg:MaxPageSize.
+	PageWidth  float64
+	PageHeight float64
+	PageUnit   string
+}
+
+// Packet encodes x as an <x:xmpmeta> packet wrapped in xpacket
+// processing instructions, as InDesign writes it.
+func Packet(x XMP) []byte {
+	var b bytes.Buffer
+	b.WriteString("<?xpacket begin=\"\xef\xbb\xbf\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n")
+	b.WriteString(`<x:xmpmeta xmlns:x="adobe:ns:meta/" x:xmptk="Adobe XMP Core">` + "\n")
+	b.WriteString(`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">` + "\n")
+	b.WriteString(`<rdf:Description rdf:about=""` +
+		` xmlns:xmp="http://ns.adobe.com/xap/1.0/"` +
+		` xmlns:xmpMM="http://ns.adobe.com/xap/1.0/mm/"` +
+		` xmlns:stEvt="http://ns.adobe.com/xap/1.0/sType/ResourceEvent#"` +
+		` xmlns:dc="http://purl.org/dc/elements/1.1/"` +
+		` xmlns:xmpTPg="http://ns.adobe.com/xap/1.0/t/pg/"` +
+		` xmlns:stDim="http://ns.adobe.com/xap/1.0/sType/Dimensions#">` + "\n")
+
+	element(&b, "xmp:CreatorTool", x.CreatorTool)
+	element(&b, "xmp:CreateDate", x.CreateDate)
+	element(&b, "xmp:ModifyDate", x.ModifyDate)
+	element(&b, "xmpMM:DocumentID", x.DocumentID)
+	element(&b, "xmpMM:InstanceID", x.InstanceID)
+	if x.Title != "" {
+		b.WriteString(`<dc:title><rdf:Alt><rdf:li xml:lang="x-default">`)
+		escape(&b, x.Title)
+		b.WriteString("</rdf:li></rdf:Alt></dc:title>\n")
+	}
+	if len(x.SoftwareAgents) > 0 {
+		b.WriteString("<xmpMM:History><rdf:Seq>\n")
+		for i, agent := range x.SoftwareAgents {
+			b.WriteString(`<rdf:li rdf:parseType="Resource">`)
+			element(&b, "stEvt:action", "saved")
+			element(&b, "stEvt:instanceID", fmt.Sprintf("xmp.iid:%032d", i+1))
+			element(&b, "stEvt:softwareAgent", agent)
+			element(&b, "stEvt:changed", "/")
+			b.WriteString("</rdf:li>\n")
+		}
+		b.WriteString("</rdf:Seq></xmpMM:History>\n")
+	}
+	if x.NPages != 0 {
+		element(&b, "xmpTPg:NPages", fmt.Sprint(x.NPages))
+	}
+	if x.PageUnit != "" {
+		b.WriteString(`<xmpTPg:MaxPageSize rdf:parseType="Resource">`)
+		element(&b, "stDim:w", fmt.Sprintf("%f", x.PageWidth))
+		element(&b, "stDim:h", fmt.Sprintf("%f", x.PageHeight))
+		element(&b, "stDim:unit", x.PageUnit)
+		b.WriteString("</xmpTPg:MaxPageSize>\n")
+	}
+
+	b.WriteString("</rdf:Description>\n</rdf:RDF>\n</x:xmpmeta>\n")
+	b.WriteString(`<?xpacket end="r"?>`)
+	return b.Bytes()
+}
+
+// element writes <name>value</name>, or nothing when value is empty.
+func element(b *bytes.Buffer, name, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(b, "<%s>", name)
+	escape(b, value)
+	fmt.Fprintf(b, "</%s>", name)
+}
+
+func escape(b *bytes.Buffer, s string) {
+	xml.EscapeText(b, []byte(s))
+}
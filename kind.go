@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Kind is the kind of InDesign file, as recorded in the header type field.
+// Templates are the exception: they share the document signature, so
+// KindTemplate only comes from an .indt extension (see resolveKind), and a
+// template renamed to .indd is reported as a document.
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	KindDocument
+	KindTemplate
+	KindBook
+	KindLibrary
+)
+
+// String returns the human readable name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case KindDocument:
+		return "Document"
+	case KindTemplate:
+		return "Template"
+	case KindBook:
+		return "Book"
+	case KindLibrary:
+		return "Library"
+	default:
+		return "Unknown"
+	}
+}
+
+// Extension returns the usual file extension for the kind, including the dot.
+func (k Kind) Extension() string {
+	switch k {
+	case KindDocument:
+		return ".indd"
+	case KindTemplate:
+		return ".indt"
+	case KindBook:
+		return ".indb"
+	case KindLibrary:
+		return ".indl"
+	default:
+		return ""
+	}
+}
+
+// kindSignatures maps the 8-byte type field (bytes 16-23) to a Kind.
+// Templates are saved with the same "DOCUMENT" signature as documents,
+// so the header alone cannot tell an .indt from an .indd.
+var kindSignatures = map[string]Kind{
+	"DOCUMENT": KindDocument,
+	"BOOKBOOK": KindBook,
+}
+
+// kindFromType decodes the header type field.
+func kindFromType(typeField string) Kind {
+	if k, ok := kindSignatures[typeField]; ok {
+		return k
+	}
+	// Libraries have used several "LIBRARYx" revisions over the years.
+	if strings.HasPrefix(typeField, "LIBRARY") {
+		return KindLibrary
+	}
+	return KindUnknown
+}
+
+// kindFromExtension returns the Kind implied by a file name's extension.
+func kindFromExtension(filePath string) Kind {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".indd":
+		return KindDocument
+	case ".indt":
+		return KindTemplate
+	case ".indb":
+		return KindBook
+	case ".indl":
+		return KindLibrary
+	default:
+		return KindUnknown
+	}
+}
+
+// resolveKind combines the header kind with the extension. A document
+// signature on an .indt file is a template, since both share the signature.
+func resolveKind(headerKind Kind, filePath string) Kind {
+	if headerKind == KindDocument && kindFromExtension(filePath) == KindTemplate {
+		return KindTemplate
+	}
+	return headerKind
+}
+
+// checkKindExtension returns a warning when the header kind disagrees with
+// the file extension, or "" when they match (or either one is unknown).
+func checkKindExtension(headerKind Kind, filePath string) string {
+	extKind := kindFromExtension(filePath)
+	if headerKind == KindUnknown || extKind == KindUnknown {
+		return ""
+	}
+	if resolveKind(headerKind, filePath) == extKind {
+		return ""
+	}
+	return fmt.Sprintf("%s has a %s extension but its header says it is a %s (expected %s)",
+		filepath.Base(filePath), filepath.Ext(filePath), strings.ToLower(headerKind.String()), headerKind.Extension())
+}
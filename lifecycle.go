@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Date is a calendar day in the catalog, written as "2006-01-02".
+type Date struct {
+	time.Time
+}
+
+// UnmarshalJSON parses "2006-01-02"; an empty string is the zero Date.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("date must be a string: %w", err)
+	}
+	if s == "" {
+		d.Time = time.Time{}
+		return nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return fmt.Errorf("invalid date %q (want YYYY-MM-DD)", s)
+	}
+	d.Time = t
+	return nil
+}
+
+// String returns the date as "2006-01-02", or "" for the zero Date.
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(time.DateOnly)
+}
+
+// applyMinVersion drops installs older than floor from found and returns
+// the paths that were dropped, keyed by major. A zero floor keeps
+// everything.
+func applyMinVersion(found map[uint32]string, floor Version) map[uint32]string {
+	ignored := make(map[uint32]string)
+	if floor.Major == 0 {
+		return ignored
+	}
+	for major, path := range found {
+		if major < floor.Major {
+			ignored[major] = path
+			delete(found, major)
+		}
+	}
+	return ignored
+}
+
+// sortedMajors returns the majors of an install map, oldest first.
+func sortedMajors(found map[uint32]string) []uint32 {
+	majors := make([]uint32, 0, len(found))
+	for major := range found {
+		majors = append(majors, major)
+	}
+	sort.Slice(majors, func(i, j int) bool { return majors[i] < majors[j] })
+	return majors
+}
+
+// lifecycleWarnings returns what is worth telling the user before
+// launching v on goos: that it is past end of support, or that it is
+// known not to run on current releases of the OS.
+func lifecycleWarnings(v Version, now time.Time, goos string) []string {
+	e, ok := catalog.lookup(v.Major)
+	if !ok {
+		return nil
+	}
+	var warnings []string
+	// Compare calendar days: the end-of-support day itself is still supported.
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if !e.EndOfSupport.IsZero() && today.After(e.EndOfSupport.Time) {
+		warnings = append(warnings, fmt.Sprintf("InDesign %s is past end of support (since %s)", v.Name(), e.EndOfSupport.Format("January 2006")))
+	}
+	if runs, ok := e.RunsOnCurrentOS[goos]; ok && !runs {
+		warnings = append(warnings, fmt.Sprintf("InDesign %s does not run reliably on current %s releases", v.Name(), osName(goos)))
+	}
+	return warnings
+}
+
+// osName returns the marketing name of a GOOS.
+func osName(goos string) string {
+	switch goos {
+	case "darwin":
+		return "macOS"
+	case "windows":
+		return "Windows"
+	default:
+		return goos
+	}
+}
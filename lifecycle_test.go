@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyMinVersion(t *testing.T) {
+	found := map[uint32]string{5: "cs3", 8: "cs6", 19: "2024"}
+	ignored := applyMinVersion(found, Version{Major: 8})
+	if len(found) != 2 || found[8] != "cs6" || found[19] != "2024" {
+		t.Errorf("kept %v, want CS6 and 2024", found)
+	}
+	if len(ignored) != 1 || ignored[5] != "cs3" {
+		t.Errorf("ignored %v, want CS3", ignored)
+	}
+	if ignored := applyMinVersion(found, Version{}); len(ignored) != 0 || len(found) != 2 {
+		t.Errorf("zero minimum ignored %v", ignored)
+	}
+}
+
+func TestLifecycleWarnings(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	w := lifecycleWarnings(Version{Major: 8}, now, "darwin")
+	if len(w) != 2 || !strings.Contains(w[0], "CS6 is past end of support") || !strings.Contains(w[1], "macOS") {
+		t.Errorf("CS6 warnings = %q, want end of support and macOS", w)
+	}
+	if w := lifecycleWarnings(Version{Major: 21}, now, "windows"); len(w) != 0 {
+		t.Errorf("2026 warnings = %q, want none", w)
+	}
+	// Not past end of support yet on the day it ends.
+	e, _ := catalog.lookup(19)
+	noon := e.EndOfSupport.Add(12 * time.Hour)
+	if w := lifecycleWarnings(Version{Major: 19}, noon, "windows"); len(w) != 0 {
+		t.Errorf("2024 warnings on its last day = %q, want none", w)
+	}
+	if w := lifecycleWarnings(Version{Major: 19}, noon.AddDate(0, 0, 1), "windows"); len(w) != 1 {
+		t.Errorf("2024 warnings the day after = %q, want end of support", w)
+	}
+	if w := lifecycleWarnings(Version{Major: 99}, now, "windows"); w != nil {
+		t.Errorf("unknown version warnings = %q, want none", w)
+	}
+}
+
+func TestCatalogDates(t *testing.T) {
+	c, err := parseCatalog([]byte(`{"versions": [{"major": 19, "released": "2023-10-10", "endOfSupport": ""}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, _ := c.lookup(19); e.Released.String() != "2023-10-10" || !e.EndOfSupport.IsZero() {
+		t.Errorf("dates = %v, %v", e.Released, e.EndOfSupport)
+	}
+	if _, err := parseCatalog([]byte(`{"versions": [{"major": 19, "released": "October 2023"}]}`)); err == nil {
+		t.Error("accepted a malformed date")
+	}
+	if _, err := resolveVersion(catalog.MinVersion); err != nil {
+		t.Errorf("built-in minimum version: %v", err)
+	}
+}
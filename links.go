@@ -0,0 +1,151 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+)
+
+// linksFolder is the folder InDesign's Package command collects links into.
+const linksFolder = "Links"
+
+// Link resolution results.
+const (
+	linkFound       = "ok"
+	linkInFolder    = "found in Links folder"
+	linkNextToDoc   = "found next to document"
+	linkMissingText = "MISSING"
+)
+
+// windowsDrivePath matches "/C:/..." as produced by file:///C:/... URLs.
+var windowsDrivePath = regexp.MustCompile(`^/[A-Za-z]:/`)
+
+// linkLocalPath turns a recorded link path into a path for this OS.
+// InDesign writes file:// URLs, but plain paths show up as well.
+func linkLocalPath(recorded string) string {
+	p := recorded
+	if strings.HasPrefix(strings.ToLower(p), "file:") {
+		if u, err := url.Parse(p); err == nil {
+			p = u.Path
+			if u.Host != "" && u.Host != "localhost" {
+				// UNC share: file://server/share/x -> //server/share/x
+				p = "//" + u.Host + p
+			}
+		}
+		if windowsDrivePath.MatchString(p) {
+			p = p[1:]
+		}
+	}
+	return filepath.FromSlash(p)
+}
+
+// linkBaseName returns the file name of a recorded path, whichever
+// separator the saving machine used. ':' only separates classic Mac (HFS)
+// paths such as "Macintosh HD:Images:photo.tif"; in a path with slashes it
+// is part of the name.
+func linkBaseName(p string) string {
+	seps := `/\`
+	if !strings.ContainsAny(p, seps) {
+		seps = ":"
+	}
+	if i := strings.LastIndexAny(p, seps); i >= 0 {
+		return p[i+1:]
+	}
+	return p
+}
+
+// resolveLink looks for a linked file at its recorded path, then in the
+// document's Links folder, then next to the document. It returns the path
+// found and how it was found, or "" and linkMissingText.
+func resolveLink(link LinkedAsset, docPath string) (string, string) {
+	local := linkLocalPath(link.FilePath)
+	if fileExists(local) {
+		return local, linkFound
+	}
+
+	name := linkBaseName(local)
+	if name == "" {
+		return "", linkMissingText
+	}
+	docDir := filepath.Dir(docPath)
+	if p := filepath.Join(docDir, linksFolder, name); fileExists(p) {
+		return p, linkInFolder
+	}
+	if p := filepath.Join(docDir, name); fileExists(p) {
+		return p, linkNextToDoc
+	}
+	return "", linkMissingText
+}
+
+// fileExists reports whether p exists and is not a directory.
+func fileExists(p string) bool {
+	info, err := os.Stat(p)
+	return err == nil && !info.IsDir()
+}
+
+// runLinks implements the "links" command: it lists every linked asset and
+// whether it resolves on disk.
+func runLinks(args []string) error {
+	fs := flag.NewFlagSet("links", flag.ExitOnError)
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: %s", commands["links"].usage)
+	}
+	filePath, err := filepath.Abs(positional[0])
+	if err != nil {
+		return fmt.Errorf("could not get absolute path for file: %w", err)
+	}
+
+	md, err := getXMPMetadata(filePath)
+	if err != nil {
+		return fmt.Errorf("error reading XMP from '%s': %w", filePath, err)
+	}
+
+	fmt.Printf("File: %s\n", filePath)
+	if len(md.Links) == 0 {
+		fmt.Println("No linked assets recorded.")
+		return nil
+	}
+
+	missing := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LINK\tSTATUS\tRESOLVED PATH")
+	for _, link := range md.Links {
+		resolved, status := resolveLink(link, filePath)
+		if status == linkMissingText {
+			missing++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", link.FilePath, status, resolved)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("%d link(s), %d missing\n", len(md.Links), missing)
+	return nil
+}
+
+// linksWarning returns a launch warning listing the document's missing
+// links, or "" when every link resolves.
+func linksWarning(md *XMPMetadata, docPath string) string {
+	if md == nil {
+		return ""
+	}
+	var names []string
+	for _, link := range md.Links {
+		if _, status := resolveLink(link, docPath); status == linkMissingText {
+			names = append(names, linkBaseName(linkLocalPath(link.FilePath)))
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d missing link(s): %s", len(names), strings.Join(names, ", "))
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLinkLocalPath(t *testing.T) {
+	tests := []struct {
+		recorded, want string
+	}{
+		{"file:///Users/jane/Images/photo%20one.tif", "/Users/jane/Images/photo one.tif"},
+		{"file://localhost/Users/jane/photo.tif", "/Users/jane/photo.tif"},
+		{"file:///C:/Projects/Images/photo.tif", "C:/Projects/Images/photo.tif"},
+		{"file://fileserver/share/Images/photo.tif", "//fileserver/share/Images/photo.tif"},
+		{"/Volumes/Work/photo.tif", "/Volumes/Work/photo.tif"},
+	}
+	for _, tt := range tests {
+		if got, want := linkLocalPath(tt.recorded), filepath.FromSlash(tt.want); got != want {
+			t.Errorf("linkLocalPath(%q) = %q, want %q", tt.recorded, got, want)
+		}
+	}
+}
+
+func TestLinkBaseName(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{"/Users/jane/Images/photo.tif", "photo.tif"},
+		{`C:\Projects\Images\photo.tif`, "photo.tif"},
+		{`\\fileserver\share\photo.tif`, "photo.tif"},
+		{"Macintosh HD:Images:photo.tif", "photo.tif"},
+		{"/Volumes/Work/Shoot 10:30.tif", "Shoot 10:30.tif"},
+		{"photo.tif", "photo.tif"},
+		{"/Users/jane/", ""},
+	}
+	for _, tt := range tests {
+		if got := linkBaseName(tt.path); got != tt.want {
+			t.Errorf("linkBaseName(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestResolveLink(t *testing.T) {
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "Job", "Brochure.indd")
+	for _, name := range []string{
+		"Job/Brochure.indd",
+		"Job/Links/packaged.tif",
+		"Job/beside.tif",
+		"Elsewhere/original.tif",
+	} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A folder where a link used to be, with a packaged copy of the file.
+	folder := filepath.Join(dir, "Elsewhere", "replaced.tif")
+	if err := os.Mkdir(folder, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Job", "Links", "replaced.tif"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	original := filepath.Join(dir, "Elsewhere", "original.tif")
+	originalURL := "file://" + filepath.ToSlash(original)
+	if !strings.HasPrefix(originalURL, "file:///") {
+		originalURL = "file:///" + filepath.ToSlash(original) // C:/...
+	}
+	tests := []struct {
+		recorded, wantPath, wantHow string
+	}{
+		{original, original, linkFound},
+		{originalURL, original, linkFound},
+		{"/Volumes/Gone/packaged.tif", filepath.Join(dir, "Job", "Links", "packaged.tif"), linkInFolder},
+		{"Macintosh HD:Images:packaged.tif", filepath.Join(dir, "Job", "Links", "packaged.tif"), linkInFolder},
+		{`\\fileserver\share\beside.tif`, filepath.Join(dir, "Job", "beside.tif"), linkNextToDoc},
+		{folder, filepath.Join(dir, "Job", "Links", "replaced.tif"), linkInFolder},
+		{"/Volumes/Gone/missing.tif", "", linkMissingText},
+		{"", "", linkMissingText},
+	}
+	for _, tt := range tests {
+		gotPath, gotHow := resolveLink(LinkedAsset{FilePath: tt.recorded}, doc)
+		if gotPath != tt.wantPath || gotHow != tt.wantHow {
+			t.Errorf("resolveLink(%q) = %q, %q; want %q, %q", tt.recorded, gotPath, gotHow, tt.wantPath, tt.wantHow)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// While a document is open, InDesign keeps a lock file next to it named
+// "~<document name>~<6 random characters>.idlk". Document names longer
+// than lockNameLength characters are truncated, so a lock with a name of
+// exactly that length matches any document whose name starts with it. The
+// lock holds the user and machine that opened the document, as short
+// strings (usually UTF-16).
+
+// lockNameLength is how many characters of the document name InDesign
+// keeps in a lock file name.
+const lockNameLength = 16
+
+// lockFilePattern matches lock file names and captures the document part.
+var lockFilePattern = regexp.MustCompile(`(?i)^~(.+)~[A-Za-z0-9]{6}\.idlk$`)
+
+// maxLockSize guards against reading something large by mistake; real
+// lock files are well under a kilobyte.
+const maxLockSize = 64 << 10
+
+// LockFile is a decoded .idlk lock file.
+type LockFile struct {
+	// Path is the lock file itself.
+	Path string
+	// User and Machine identify who has the document open; either may be
+	// empty when the lock could not be decoded.
+	User    string
+	Machine string
+	// ModTime is when the lock was written, i.e. when the document was opened.
+	ModTime time.Time
+}
+
+// Owner formats the lock owner as "jsmith on MAC-042".
+func (l LockFile) Owner() string {
+	switch {
+	case l.User != "" && l.Machine != "":
+		return fmt.Sprintf("%s on %s", l.User, l.Machine)
+	case l.User != "":
+		return l.User
+	case l.Machine != "":
+		return "someone on " + l.Machine
+	default:
+		return "an unknown user"
+	}
+}
+
+// isOwnLock reports whether the lock was written by the current user on
+// this machine, in which case InDesign simply brings the document forward.
+func (l LockFile) isOwnLock() bool {
+	host, err := os.Hostname()
+	if err != nil || !strings.EqualFold(hostShortName(host), hostShortName(l.Machine)) {
+		return false
+	}
+	u, err := user.Current()
+	if err != nil {
+		return false
+	}
+	name := u.Username
+	if i := strings.LastIndex(name, `\`); i >= 0 {
+		name = name[i+1:] // DOMAIN\user on Windows
+	}
+	return strings.EqualFold(name, l.User)
+}
+
+// hostShortName drops the domain part of a host name.
+func hostShortName(host string) string {
+	host, _, _ = strings.Cut(host, ".")
+	return host
+}
+
+// lockTarget returns the document part of a lock file name, or false if
+// name is not a lock file.
+func lockTarget(name string) (string, bool) {
+	m := lockFilePattern.FindStringSubmatch(name)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// lockMatches reports whether the document part of a lock file name
+// refers to the document named docName.
+func lockMatches(target, docName string) bool {
+	target = strings.ToLower(target)
+	docName = strings.ToLower(docName)
+	base := strings.TrimSuffix(docName, strings.ToLower(filepath.Ext(docName)))
+	if target == docName || target == base {
+		return true
+	}
+	// Only a truncated name stands for a longer one: "Bro" is not "Brochure".
+	return utf8.RuneCountInString(target) == lockNameLength && strings.HasPrefix(base, target)
+}
+
+// findLockFile looks for a lock file for docPath in the document's folder.
+// It returns nil when the document is not locked.
+func findLockFile(docPath string) (*LockFile, error) {
+	entries, err := os.ReadDir(filepath.Dir(docPath))
+	if err != nil {
+		return nil, fmt.Errorf("could not read folder: %w", err)
+	}
+	docName := filepath.Base(docPath)
+	for _, e := range entries {
+		target, ok := lockTarget(e.Name())
+		if !ok || e.IsDir() || !lockMatches(target, docName) {
+			continue
+		}
+		lock, err := readLockFile(filepath.Join(filepath.Dir(docPath), e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		return &lock, nil
+	}
+	return nil, nil
+}
+
+// readLockFile decodes the owner of a lock file.
+func readLockFile(path string) (LockFile, error) {
+	lock := LockFile{Path: path}
+	info, err := os.Stat(path)
+	if err != nil {
+		return lock, fmt.Errorf("could not open lock file: %w", err)
+	}
+	lock.ModTime = info.ModTime()
+	if info.Size() > maxLockSize {
+		return lock, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return lock, fmt.Errorf("could not read lock file: %w", err)
+	}
+
+	// The first two strings are the user and the machine.
+	fields := lockStrings(data)
+	if len(fields) > 0 {
+		lock.User = fields[0]
+	}
+	if len(fields) > 1 {
+		lock.Machine = fields[1]
+	}
+	return lock, nil
+}
+
+// lockStrings returns the printable strings in a lock file. The encoding
+// (UTF-16LE, UTF-16BE or 8-bit) is guessed from where the zero bytes are:
+// UTF-16 text of Latin names has a zero in every other byte.
+func lockStrings(data []byte) []string {
+	var zeroEven, zeroOdd int
+	for i, b := range data {
+		if b == 0 {
+			if i%2 == 0 {
+				zeroEven++
+			} else {
+				zeroOdd++
+			}
+		}
+	}
+
+	var text []rune
+	switch {
+	case zeroOdd > len(data)/4 && zeroOdd > 2*zeroEven:
+		text = utf16Runes(data, binary.LittleEndian)
+	case zeroEven > len(data)/4 && zeroEven > 2*zeroOdd:
+		text = utf16Runes(data, binary.BigEndian)
+	default:
+		text = bytes.Runes(data)
+	}
+
+	// Split on anything that cannot be part of a user or machine name.
+	// Single characters are usually length prefixes that happen to be
+	// printable, so they are dropped.
+	var fields []string
+	for _, f := range strings.FieldsFunc(string(text), func(r rune) bool {
+		return !unicode.IsPrint(r) || unicode.IsSpace(r) || r == unicode.ReplacementChar
+	}) {
+		if len([]rune(f)) > 1 {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+func utf16Runes(data []byte, order binary.ByteOrder) []rune {
+	u := make([]uint16, len(data)/2)
+	for i := range u {
+		u[i] = order.Uint16(data[2*i:])
+	}
+	return utf16.Decode(u)
+}
+
+// lockAction is what to do when a document is already open elsewhere.
+type lockAction int
+
+const (
+	// lockAsk asks the user; see askLockAction.
+	lockAsk lockAction = iota
+	// lockAbort does not launch.
+	lockAbort
+	// lockCopy opens a copy of the document in a temporary folder.
+	lockCopy
+	// lockContinue opens the document anyway.
+	lockContinue
+)
+
+// lockActions lists every action, in the order shown in help texts.
+var lockActions = []lockAction{lockAsk, lockAbort, lockCopy, lockContinue}
+
+// String returns the action name.
+func (a lockAction) String() string {
+	switch a {
+	case lockAsk:
+		return "ask"
+	case lockAbort:
+		return "abort"
+	case lockCopy:
+		return "copy"
+	case lockContinue:
+		return "continue"
+	default:
+		return fmt.Sprintf("lockAction(%d)", int(a))
+	}
+}
+
+// parseLockAction returns the action with the given name.
+func parseLockAction(name string) (lockAction, error) {
+	var names []string
+	for _, a := range lockActions {
+		if strings.EqualFold(name, a.String()) {
+			return a, nil
+		}
+		names = append(names, a.String())
+	}
+	return 0, fmt.Errorf("unknown lock action %q (want one of: %s)", name, strings.Join(names, ", "))
+}
+
+// lockMessage is the text shown when a document is already open.
+func lockMessage(docPath string, lock *LockFile) string {
+	msg := fmt.Sprintf("%s is already open by %s", filepath.Base(docPath), lock.Owner())
+	if !lock.ModTime.IsZero() {
+		msg += fmt.Sprintf(" (since %s)", lock.ModTime.Format("Jan 2 15:04"))
+	}
+	return msg
+}
+
+// lockDocumentExtensions are the files InDesign writes lock files for.
+var lockDocumentExtensions = map[string]bool{
+	".indd": true, ".indt": true, ".indb": true, ".indl": true,
+}
+
+// lockDocument returns the document a lock file belongs to, or "" if no
+// matching document exists next to it.
+func lockDocument(lockPath string) string {
+	target, ok := lockTarget(filepath.Base(lockPath))
+	if !ok {
+		return ""
+	}
+	dir := filepath.Dir(lockPath)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, e := range entries {
+		if e.IsDir() || !lockDocumentExtensions[strings.ToLower(filepath.Ext(e.Name()))] {
+			continue
+		}
+		if lockMatches(target, e.Name()) {
+			return filepath.Join(dir, e.Name())
+		}
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestLockMatches(t *testing.T) {
+	tests := []struct {
+		lockName, docName string
+		want              bool
+	}{
+		{"~Brochure~a1B2c3.idlk", "Brochure.indd", true},
+		{"~brochure~a1B2c3.IDLK", "Brochure.indd", true},
+		{"~Annual Report 20~x9y8z7.idlk", "Annual Report 2024 final.indd", true},
+		{"~Brochure~a1B2c3.idlk", "Flyer.indd", false},
+		{"~Bro~a1B2c3.idlk", "Brochure.indd", false},
+		{"~a~a1B2c3.idlk", "annual.indd", false},
+		{"~Brochure~toolong.idlk", "Brochure.indd", false},
+		{"Brochure.idlk", "Brochure.indd", false},
+	}
+	for _, tt := range tests {
+		target, ok := lockTarget(tt.lockName)
+		if got := ok && lockMatches(target, tt.docName); got != tt.want {
+			t.Errorf("%q vs %q: got %v, want %v", tt.lockName, tt.docName, got, tt.want)
+		}
+	}
+}
+
+func TestReadLockFile(t *testing.T) {
+	// Length-prefixed UTF-16LE strings, as written on Windows.
+	var data []byte
+	for _, s := range []string{"jsmith", "MAC-042"} {
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(s)))
+		for _, u := range utf16.Encode([]rune(s)) {
+			data = binary.LittleEndian.AppendUint16(data, u)
+		}
+	}
+
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "Brochure.indd")
+	if err := os.WriteFile(doc, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "~Brochure~a1B2c3.idlk"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := findLockFile(doc)
+	if err != nil || lock == nil {
+		t.Fatalf("findLockFile = %v, %v", lock, err)
+	}
+	if got, want := lock.Owner(), "jsmith on MAC-042"; got != want {
+		t.Errorf("Owner() = %q, want %q", got, want)
+	}
+}
+
+func TestLockStrings(t *testing.T) {
+	utf16Data := func(order binary.AppendByteOrder) []byte {
+		var data []byte
+		for _, s := range []string{"jsmith", "MAC-042"} {
+			data = order.AppendUint32(data, uint32(len(s)))
+			for _, u := range utf16.Encode([]rune(s)) {
+				data = order.AppendUint16(data, u)
+			}
+		}
+		return data
+	}
+	tests := map[string][]byte{
+		"UTF-16LE": utf16Data(binary.LittleEndian),
+		"UTF-16BE": utf16Data(binary.BigEndian),
+		// A few zero terminators must not be taken for UTF-16.
+		"8-bit": []byte("jsmith\x00MAC-042\x00"),
+	}
+	for name, data := range tests {
+		fields := lockStrings(data)
+		if len(fields) != 2 || fields[0] != "jsmith" || fields[1] != "MAC-042" {
+			t.Errorf("%s: lockStrings = %q, want [jsmith MAC-042]", name, fields)
+		}
+	}
+}
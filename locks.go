@@ -0,0 +1,177 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+)
+
+// defaultOldLockAge is how old a lock must be before the "locks" command
+// reports it as old. InDesign rewrites nothing in the lock while the
+// document is open, so age alone cannot tell a crash from a long session:
+// old locks are only removed with -include-old.
+const defaultOldLockAge = 24 * time.Hour
+
+// lockState is what the "locks" command makes of a lock file.
+type lockState int
+
+const (
+	// lockActive is a recent lock whose document exists.
+	lockActive lockState = iota
+	// lockOld is a lock older than the age threshold whose document
+	// exists. It may belong to a crash or to a document left open.
+	lockOld
+	// lockOrphaned is a lock whose document is gone. It cannot protect
+	// anything, so it is always safe to remove.
+	lockOrphaned
+)
+
+// String returns the name shown in the STATE column.
+func (s lockState) String() string {
+	switch s {
+	case lockOld:
+		return "old"
+	case lockOrphaned:
+		return "orphaned"
+	default:
+		return "active"
+	}
+}
+
+// classifyLock returns the state of a lock of the given age, whose
+// document is doc ("" when missing).
+func classifyLock(doc string, age, olderThan time.Duration) lockState {
+	switch {
+	case doc == "":
+		return lockOrphaned
+	case age >= olderThan:
+		return lockOld
+	default:
+		return lockActive
+	}
+}
+
+// runLocks implements the "locks" command: it lists every lock file under
+// a folder and removes the orphaned ones (and, with -include-old, the old
+// ones) when asked to.
+func runLocks(args []string) error {
+	fs := flag.NewFlagSet("locks", flag.ExitOnError)
+	removeFlag := fs.Bool("remove", false, "Remove the orphaned locks (default is a dry run)")
+	olderThanFlag := fs.Duration("older-than", defaultOldLockAge, "Report locks older than this as old")
+	includeOldFlag := fs.Bool("include-old", false, "With -remove, also remove old locks whose document still exists")
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: %s", commands["locks"].usage)
+	}
+	root := positional[0]
+
+	locks, err := scanLocks(root)
+	if err != nil {
+		return err
+	}
+	if len(locks) == 0 {
+		fmt.Printf("No lock files found under %s\n", root)
+		return nil
+	}
+
+	now := time.Now()
+	removable, removed := 0, 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LOCK\tOWNER\tAGE\tDOCUMENT\tSTATE\tACTION")
+	for _, lock := range locks {
+		age := now.Sub(lock.ModTime)
+		doc := lockDocument(lock.Path)
+		docText := filepath.Base(doc)
+		if doc == "" {
+			docText = "(missing)"
+		}
+
+		state := classifyLock(doc, age, *olderThanFlag)
+		action := "keep"
+		if state == lockOrphaned || (state == lockOld && *includeOldFlag) {
+			removable++
+			action = "would remove"
+			if *removeFlag {
+				if err := os.Remove(lock.Path); err != nil {
+					action = fmt.Sprintf("FAILED: %v", err)
+				} else {
+					action = "removed"
+					removed++
+				}
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", lock.Path, lock.Owner(), formatAge(age), docText, state, action)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("%d lock(s), %d to remove", len(locks), removable)
+	if *removeFlag {
+		fmt.Printf(", %d removed\n", removed)
+	} else if removable > 0 {
+		fmt.Printf(" (dry run; use -remove to delete them)\n")
+	} else {
+		fmt.Println()
+	}
+	return nil
+}
+
+// scanLocks returns every lock file under root. Unreadable files and
+// folders are reported and skipped; only an unreadable root fails.
+func scanLocks(root string) ([]LockFile, error) {
+	var locks []LockFile
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			// Unreadable files and folders on a shared volume are skipped,
+			// not fatal.
+			fmt.Fprintf(os.Stderr, "Skipping %s: %v\n", path, err)
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if _, ok := lockTarget(d.Name()); !ok {
+			return nil
+		}
+		lock, err := readLockFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping %s: %v\n", path, err)
+			return nil
+		}
+		locks = append(locks, lock)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not scan '%s': %w", root, err)
+	}
+	return locks, nil
+}
+
+// formatAge formats a duration as days, hours and minutes, e.g. "3d 4h".
+func formatAge(d time.Duration) string {
+	d = d.Round(time.Minute)
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
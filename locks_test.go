@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClassifyLock(t *testing.T) {
+	tests := []struct {
+		doc  string
+		age  time.Duration
+		want lockState
+	}{
+		{"Brochure.indd", time.Hour, lockActive},
+		{"Brochure.indd", 3 * 24 * time.Hour, lockOld},
+		{"", time.Hour, lockOrphaned},
+		{"", 3 * 24 * time.Hour, lockOrphaned},
+	}
+	for _, tt := range tests {
+		if got := classifyLock(tt.doc, tt.age, defaultOldLockAge); got != tt.want {
+			t.Errorf("classifyLock(%q, %v) = %v, want %v", tt.doc, tt.age, got, tt.want)
+		}
+	}
+}
+
+func TestRunLocks(t *testing.T) {
+	root := t.TempDir()
+	write := func(name string, age time.Duration) string {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("jsmith\x00MAC-042\x00"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Now().Add(-age)
+		if err := os.Chtimes(p, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	write("Jobs/Brochure.indd", 0)
+	active := write("Jobs/~Brochure~a1B2c3.idlk", time.Hour)
+	write("Jobs/Archive/Flyer.indd", 0)
+	old := write("Jobs/Archive/~Flyer~d4E5f6.idlk", 3*24*time.Hour)
+	orphaned := write("~Gone~g7H8i9.idlk", time.Hour)
+	write("notes.txt", 0)
+
+	locks, err := scanLocks(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locks) != 3 {
+		t.Errorf("scanLocks found %d locks, want 3: %v", len(locks), locks)
+	}
+	for _, l := range locks {
+		if l.Owner() != "jsmith on MAC-042" {
+			t.Errorf("%s: owner %q", l.Path, l.Owner())
+		}
+	}
+
+	exists := func(p string) bool {
+		_, err := os.Stat(p)
+		return err == nil
+	}
+	steps := []struct {
+		args                  []string
+		active, old, orphaned bool
+	}{
+		{[]string{root}, true, true, true},                              // dry run
+		{[]string{"-remove", root}, true, true, false},                  // orphaned only
+		{[]string{"-remove", "-include-old", root}, true, false, false}, // and old
+	}
+	for _, s := range steps {
+		if err := runLocks(s.args); err != nil {
+			t.Fatalf("locks %v: %v", s.args, err)
+		}
+		if exists(active) != s.active || exists(old) != s.old || exists(orphaned) != s.orphaned {
+			t.Errorf("after locks %v: active %v, old %v, orphaned %v; want %v, %v, %v",
+				s.args, exists(active), exists(old), exists(orphaned), s.active, s.old, s.orphaned)
+		}
+	}
+
+	if _, err := scanLocks(filepath.Join(root, "missing")); err == nil {
+		t.Error("scanLocks of a missing folder succeeded")
+	}
+}
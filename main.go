@@ -6,10 +6,12 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"sort"
+	"time"
 
 	"github.com/gen2brain/beeep"
 )
@@ -23,9 +25,19 @@ var iconErr []byte
 var iconInfo []byte
 
 func main() {
+	// Before the flags, so the user catalog's minVersion is the default.
+	loadUserCatalog()
 
 	registerFlag := flag.Bool("register", false, "Register as default .indd handler")
 	unregisterFlag := flag.Bool("unregister", false, "Unregister as default .indd handler")
+	debugFlag := flag.Bool("debug", false, "Print both database master pages when opening a file, and installs skipped during discovery")
+	checkFontsFlag := flag.Bool("check-fonts", false, "Warn about missing fonts before launching")
+	checkLinksFlag := flag.Bool("check-links", false, "Warn about missing linked files before launching")
+	templatePolicyFlag := flag.String("template-policy", policyExact.String(), "Launch policy for templates (.indt): oldest-compatible, newest, exact or read-only-copy")
+	libraryPolicyFlag := flag.String("library-policy", policyReadOnlyCopy.String(), "Launch policy for libraries (.indl): oldest-compatible, newest, exact or read-only-copy")
+	lockActionFlag := flag.String("lock-action", lockAsk.String(), "What to do when the file is already open elsewhere: ask, abort, copy or continue")
+	versionFlag := flag.String("version", "", "Open with this InDesign version (e.g. 2024, CC2019, 19) instead of choosing one")
+	minVersionFlag := flag.String("min-version", catalog.MinVersion, "Ignore installed InDesign versions older than this (empty for no minimum)")
 	beeep.AppName = "InDesign Launcher"
 
 	// Parse the flags
@@ -55,14 +67,51 @@ func main() {
 	// Check if a file path was provided
 	if flag.NArg() == 0 {
 		log.Println("Usage: indesign-launcher [options] <path-to-file.indd>")
+		log.Println("       indesign-launcher <command> [arguments]")
 		log.Println("Options:")
 		flag.PrintDefaults()
+		printCommandUsage()
 		return
 	}
 
+	// Route subcommands (e.g. "history <file>")
+	if cmd, ok := commands[flag.Arg(0)]; ok {
+		if err := cmd.run(flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	debugDiscovery = *debugFlag
+
 	// Get the file path from the remaining arguments
 	filePath := flag.Arg(0)
-	if err := openFile(filePath); err != nil {
+	opts := launchOptions{
+		debug:      *debugFlag,
+		checkFonts: *checkFontsFlag,
+		checkLinks: *checkLinksFlag,
+	}
+	var err error
+	if opts.templatePolicy, err = parseLaunchPolicy(*templatePolicyFlag); err != nil {
+		log.Fatalf("Invalid -template-policy: %v", err)
+	}
+	if opts.libraryPolicy, err = parseLaunchPolicy(*libraryPolicyFlag); err != nil {
+		log.Fatalf("Invalid -library-policy: %v", err)
+	}
+	if opts.lockAction, err = parseLockAction(*lockActionFlag); err != nil {
+		log.Fatalf("Invalid -lock-action: %v", err)
+	}
+	if *versionFlag != "" {
+		if opts.version, err = resolveVersion(*versionFlag); err != nil {
+			log.Fatalf("Invalid -version: %v", err)
+		}
+	}
+	if *minVersionFlag != "" {
+		if opts.minVersion, err = resolveVersion(*minVersionFlag); err != nil {
+			log.Fatalf("Invalid -min-version: %v", err)
+		}
+	}
+	if err := openFile(filePath, opts); err != nil {
 		log.Fatal(err)
 	}
 
@@ -82,6 +131,12 @@ func launchApp(appPath, filePath string) error {
 		// '-a' specifies the application (appPath)
 		// and the final argument is the file to open.
 		cmd = exec.Command("open", "-a", appPath, filePath)
+	case "linux":
+		// On Linux, InDesign runs under Wine; see wine.go.
+		var err error
+		if cmd, err = wineLaunchCommand(appPath, filePath); err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
 	}
@@ -91,32 +146,71 @@ func launchApp(appPath, filePath string) error {
 	return cmd.Start()
 }
 
-// It returns the app path and the major version it selected.
-func selectVersionToLaunch(fileMajor uint32, installed map[uint32]string) (string, uint32) {
+// selectVersionToLaunch picks an installed version for a file according to policy.
+// It returns the app path and the version it selected, or "" and the zero
+// Version when policyExact is used and the file's version is not installed.
+// Any release of a major opens every file of that major, so only majors
+// decide compatibility.
+func selectVersionToLaunch(fileVersion Version, installed map[uint32]string, policy launchPolicy) (string, Version) {
+
+	// Exact matches only; the caller refuses to launch otherwise.
+	if policy == policyExact {
+		if appPath, ok := installed[fileVersion.Major]; ok {
+			return appPath, Version{Major: fileVersion.Major}
+		}
+		return "", Version{}
+	}
 
-	// Create a sorted list of all installed major versions
-	var keys []uint32
-	for k := range installed {
-		keys = append(keys, k)
+	// Create a sorted list of all installed versions
+	var versions []Version
+	for major := range installed {
+		versions = append(versions, Version{Major: major})
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Compare(versions[j]) < 0 })
+	latestVersion := versions[len(versions)-1]
+
+	// Forward-compatible formats simply go to the newest version.
+	if policy == policyNewest {
+		return installed[latestVersion.Major], latestVersion
 	}
-	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
 
 	// Case 1: Find the lowest compatible version
 	// Loop from low to high
-	for _, major := range keys {
-		if major >= fileMajor {
+	for _, v := range versions {
+		if v.Major >= fileVersion.Major {
 			// Found it! This is the oldest, compatible version.
-			return installed[major], major
+			return installed[v.Major], v
 		}
 	}
 
 	// Case 2: No compatible version found.
 	// Fallback to the latest installed version.
-	latestVersion := keys[len(keys)-1]
-	return installed[latestVersion], latestVersion
+	return installed[latestVersion.Major], latestVersion
+}
+
+// launchOptions holds the command-line switches that affect openFile.
+type launchOptions struct {
+	// debug prints both database master pages before launching.
+	debug bool
+	// checkFonts warns about fonts that are not installed locally.
+	checkFonts bool
+	// checkLinks warns about linked files that do not resolve on disk.
+	checkLinks bool
+	// templatePolicy and libraryPolicy replace the default policy for
+	// templates (.indt) and libraries (.indl).
+	templatePolicy launchPolicy
+	libraryPolicy  launchPolicy
+	// lockAction is what to do when an .idlk lock shows that someone else
+	// already has the file open.
+	lockAction lockAction
+	// version, when set, is launched instead of the version the policy picks.
+	version Version
+	// minVersion is the oldest version that may be launched; older installs
+	// are ignored. Zero means no minimum.
+	minVersion Version
 }
 
-func openFile(filePath string) error {
+func openFile(filePath string, opts launchOptions) error {
 	// 1. Get and clean the file path
 	absPath, err := filepath.Abs(filePath)
 	if err != nil {
@@ -125,42 +219,200 @@ func openFile(filePath string) error {
 	}
 
 	// 2. Get the file's required major version
-	fileMajorVersion, err := getInDesignVersion(absPath)
+	fmt.Printf("File: %s\n", absPath)
+	var target launchTarget
+	switch {
+	case isIDMLFile(absPath):
+		target, err = describeIDMLFile(absPath)
+	case isXMLFormatFile(absPath):
+		target, err = describeXMLFile(absPath)
+	default:
+		target, err = describeInDesignFile(absPath, opts)
+	}
 	if err != nil {
 		beeep.Alert("Invalid file", fmt.Sprintf("Error reading file '%s': %v", absPath, err), iconErr)
 		return fmt.Errorf("error reading file '%s': %w", absPath, err)
 	}
-	fmt.Printf("File: %s\n", absPath)
-	fmt.Printf("Detected File Version: %s (Major: %d)\n", versionMap[fileMajorVersion], fileMajorVersion)
+	fileVersion := target.version
 
 	// 3. DISCOVER: Find all installed versions
-	installedVersions, err := findAllInstalledVersions()
+	installedVersions, ignoredVersions, err := findAllInstalledVersions(opts.minVersion)
 	if err != nil {
 		beeep.Alert("InDesign error", fmt.Sprintf("Error detecting InDesign versions: %v", err), iconErr)
 		return fmt.Errorf("error finding installed versions: %w", err)
 	}
+	for _, major := range sortedMajors(ignoredVersions) {
+		fmt.Printf("... ignoring InDesign %s at %s (older than the minimum, %s)\n", Version{Major: major}.Name(), ignoredVersions[major], opts.minVersion.Name())
+	}
+	if len(installedVersions) == 0 && len(ignoredVersions) > 0 {
+		msg := fmt.Sprintf("Only InDesign versions older than %s are installed (see -min-version)", opts.minVersion.Name())
+		beeep.Alert("InDesign not found", msg, iconErr)
+		return fmt.Errorf("failed: %s", msg)
+	}
 	if len(installedVersions) == 0 {
 		beeep.Alert("InDesign not found", "Failed: No InDesign versions found on this system", iconErr)
 		return fmt.Errorf("failed: No InDesign versions found on this system")
 	}
 
 	// 4. DECIDE: Select the best version to use
-	appPath, launchedVersion := selectVersionToLaunch(fileMajorVersion, installedVersions)
-
-	if launchedVersion >= fileMajorVersion {
-		fmt.Printf("... launching compatible version: %s (Major: %d)\n", versionMap[launchedVersion], launchedVersion)
+	forced := opts.version.Major != 0
+	var appPath string
+	var launchedVersion Version
+	if forced {
+		// -version overrides the policy.
+		appPath, launchedVersion = selectVersionToLaunch(opts.version, installedVersions, policyExact)
+		if _, ok := ignoredVersions[opts.version.Major]; ok {
+			msg := fmt.Sprintf("InDesign %s was requested with -version but is older than the minimum version, %s", opts.version.Name(), opts.minVersion.Name())
+			beeep.Alert("Version not allowed", msg, iconErr)
+			return fmt.Errorf("refusing to launch: %s", msg)
+		}
+		if appPath == "" {
+			msg := fmt.Sprintf("InDesign %s was requested with -version but is not installed", opts.version.Name())
+			beeep.Alert("Version not installed", msg, iconErr)
+			return fmt.Errorf("refusing to launch: %s", msg)
+		}
 	} else {
+		appPath, launchedVersion = selectVersionToLaunch(fileVersion, installedVersions, target.policy)
+		if appPath == "" {
+			msg := fmt.Sprintf("%s requires exactly InDesign %s, which is not installed", filepath.Base(absPath), fileVersion.Name())
+			beeep.Alert("Version not installed", msg, iconErr)
+			return fmt.Errorf("refusing to launch: %s", msg)
+		}
+	}
+
+	switch {
+	case forced:
+		fmt.Printf("... launching requested version: %s (Major: %d)\n", launchedVersion.Name(), launchedVersion.Major)
+		if launchedVersion.Major < fileVersion.Major {
+			target.warn(fmt.Sprintf("InDesign %s cannot open files saved with %s", launchedVersion.Name(), fileVersion.Name()))
+		}
+	case target.policy == policyExact:
+		fmt.Printf("... launching exact version: %s (Major: %d)\n", launchedVersion.Name(), launchedVersion.Major)
+	case target.policy == policyNewest:
+		fmt.Printf("... launching newest installed version: %s (Major: %d)\n", launchedVersion.Name(), launchedVersion.Major)
+	case launchedVersion.Major >= fileVersion.Major:
+		fmt.Printf("... launching compatible version: %s (Major: %d)\n", launchedVersion.Name(), launchedVersion.Major)
+	default:
 		fmt.Printf("... WARNING: No compatible version found.\n")
-		fmt.Printf("... Launching latest available version: %s (Major: %d)\n", versionMap[launchedVersion], launchedVersion)
+		fmt.Printf("... Launching latest available version: %s (Major: %d)\n", launchedVersion.Name(), launchedVersion.Major)
 	}
 	fmt.Printf("Found application: %s\n", appPath)
+	for _, w := range lifecycleWarnings(launchedVersion, time.Now(), runtime.GOOS) {
+		target.warn(w)
+	}
+
+	// Someone else may already have the file open on a shared volume.
+	launchPath := absPath
+	if lock, err := findLockFile(absPath); err == nil && lock != nil && !lock.isOwnLock() {
+		msg := lockMessage(absPath, lock)
+		fmt.Printf("... WARNING: %s\n", msg)
+		action := opts.lockAction
+		if action == lockAsk {
+			action = askLockAction(msg)
+		}
+		switch action {
+		case lockCopy:
+			launchPath, err = copyToTemp(absPath)
+			if err != nil {
+				beeep.Alert("Failed", fmt.Sprintf("could not make a copy: %v", err), iconErr)
+				return fmt.Errorf("could not make a copy: %w", err)
+			}
+			fmt.Printf("... opening a copy: %s\n", launchPath)
+			target.warn(msg + "; opened a copy instead")
+		case lockContinue:
+			target.warn(msg)
+		default:
+			beeep.Alert("Already open", msg, iconErr)
+			return fmt.Errorf("not launching: %s", msg)
+		}
+	}
+
+	// A newer version would convert the file; protect the shared original.
+	if target.policy == policyReadOnlyCopy && launchedVersion.Major > fileVersion.Major && launchPath == absPath {
+		launchPath, err = makeReadOnlyCopy(absPath)
+		if err != nil {
+			beeep.Alert("Failed", fmt.Sprintf("could not make a read-only copy: %v", err), iconErr)
+			return fmt.Errorf("could not make a read-only copy: %w", err)
+		}
+		fmt.Printf("... opening a read-only copy: %s\n", launchPath)
+		target.warn(fmt.Sprintf("InDesign %s would convert this file; opened a read-only copy instead", launchedVersion.Name()))
+	}
 
 	// 5. LAUNCH
-	if err := launchApp(appPath, absPath); err != nil {
+	if err := launchApp(appPath, launchPath); err != nil {
 		beeep.Alert("Failed", fmt.Sprintf("failed to launch InDesign: %v", err), iconErr)
 		return fmt.Errorf("failed to launch InDesign: %w", err)
 	}
-	beeep.Notify("Open", fmt.Sprintf("%s opened in InDesign %s", filepath.Base(absPath), versionMap[launchedVersion]), iconInfo)
+	message := fmt.Sprintf("%s opened in InDesign %s", filepath.Base(absPath), launchedVersion.Name())
+	for _, w := range target.warnings {
+		message += "\nWarning: " + w
+	}
+	beeep.Notify("Open", message, iconInfo)
 	fmt.Println("Successfully launched!")
 	return nil
 }
+
+// describeInDesignFile reads the header and XMP of a binary InDesign file
+// (.indd, .indt, ...), prints what it found and runs the enabled preflights.
+func describeInDesignFile(absPath string, opts launchOptions) (launchTarget, error) {
+	target := launchTarget{policy: policyOldestCompatible}
+
+	masterPages, active, err := getMasterPages(absPath)
+	if err != nil {
+		return target, err
+	}
+	header := masterPages[active].Header
+	target.version = header.Version()
+	if opts.debug {
+		printMasterPages(os.Stdout, masterPages, active)
+	}
+	kind := resolveKind(header.Kind, absPath)
+	fmt.Printf("Detected File Kind: %s\n", kind)
+	target.warn(checkKindExtension(header.Kind, absPath))
+	target.policy = policyForKind(kind, opts, target.policy)
+	if target.policy != policyOldestCompatible {
+		fmt.Printf("Launch policy for %s: %s\n", kind, target.policy)
+	}
+
+	// A book opens in the version its newest chapter needs.
+	if header.Kind == KindBook {
+		return describeBookFile(absPath, target)
+	}
+
+	// The XMP packet is optional; a file without one still opens.
+	md, _ := getXMPMetadata(absPath)
+
+	// The header minor is coarse; the last save's softwareAgent records the
+	// exact point release, so prefer it when it agrees on the major.
+	var lastSave SoftwareAgent
+	var hasLastSave bool
+	if md != nil {
+		lastSave, hasLastSave = md.lastSaveAgent()
+		if hasLastSave && lastSave.Major == target.version.Major {
+			target.version.Minor = lastSave.Minor
+		}
+	}
+	fmt.Printf("Detected File Version: %s (Major: %d, Minor: %d)\n", target.version.Name(), target.version.Major, target.version.Minor)
+
+	if md != nil {
+		if md.CreatorTool != "" {
+			fmt.Printf("Created with: %s\n", md.CreatorTool)
+		}
+		if hasLastSave {
+			fmt.Printf("Last saved with: %s\n", lastSave)
+		}
+		if !md.ModifyDate.IsZero() {
+			fmt.Printf("Last modified: %s\n", md.ModifyDate.Format(time.RFC1123))
+		}
+	}
+
+	// Preflight: catch missing fonts and links before InDesign shows its
+	// own dialogs.
+	if opts.checkFonts {
+		target.warn(fontsWarning(md, absPath))
+	}
+	if opts.checkLinks {
+		target.warn(linksWarning(md, absPath))
+	}
+	return target, nil
+}
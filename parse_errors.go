@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// --- Parse Errors ---
+
+// Sentinel errors for the ways an InDesign header can be rejected. Use
+// errors.Is to test for them; errors.As with *ParseError gives the offset.
+var (
+	// ErrNotInDesign means the magic GUID does not match.
+	ErrNotInDesign = errors.New("not a valid InDesign file")
+	// ErrTruncated means the file ends before the header does.
+	ErrTruncated = errors.New("file is truncated")
+	// ErrUnknownEndian means the byte order flag is neither 1 nor 2.
+	ErrUnknownEndian = errors.New("unknown endianness flag")
+	// ErrImplausibleVersion means the major version is out of range.
+	ErrImplausibleVersion = errors.New("implausible version number")
+)
+
+// maxPlausibleMajor is the highest major version we accept. InDesign 2026
+// is major 21; anything far beyond that is garbage, not a future release.
+const maxPlausibleMajor = 99
+
+// ParseError is returned by the header parser. It records which field
+// failed and the byte offset (from the start of the file) where it lives.
+type ParseError struct {
+	// Offset is the byte offset at which parsing failed.
+	Offset int64
+	// Field is the header field being decoded, e.g. "magic number".
+	Field string
+	// Detail is optional extra information, e.g. the value found.
+	Detail string
+	// Err is one of the sentinel errors above.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("%v: %s at byte %d", e.Err, e.Field, e.Offset)
+	if e.Detail != "" {
+		msg += " (" + e.Detail + ")"
+	}
+	return msg
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// offsetBy shifts the offset of a *ParseError by base, so errors from a
+// page parser point at the right place in the file. Other errors are
+// returned unchanged.
+func offsetBy(err error, base int64) error {
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		shifted := *pe
+		shifted.Offset += base
+		return &shifted
+	}
+	return err
+}
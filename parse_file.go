@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 )
 
@@ -26,59 +27,299 @@ var magicNumber = []byte{
 // 16 (Magic) + 8 (Type) + 1 (Flag) + 4 (Padding) + 4 (Major) + 4 (Minor) = 37 bytes
 const headerSize = 37
 
-// getInDesignVersion opens the file, reads the header, and returns the version string.
-func getInDesignVersion(filePath string) (uint32, error) {
+// Endianness flag values found at byte 24 of the header.
+const (
+	endianLittle = 1
+	endianBig    = 2
+)
+
+// Header holds the fields decoded from the start of an InDesign file. Like
+// the rest of the parser it lives in package main, so only the launcher
+// itself can use it.
+type Header struct {
+	// Magic is the 16-byte GUID at bytes 0-15.
+	Magic [16]byte
+	// Type is the 8-byte type field at bytes 16-23 (e.g. "DOCUMENT").
+	Type [8]byte
+	// Kind is the file kind decoded from Type.
+	Kind Kind
+	// EndianFlag is the raw byte 24: 1 = little endian, 2 = big endian.
+	EndianFlag byte
+	// ByteOrder is the byte order selected by EndianFlag.
+	ByteOrder binary.ByteOrder
+	// MajorVersion is the application major version (e.g. 19 for InDesign 2024).
+	MajorVersion uint32
+	// MinorVersion is the application minor version.
+	MinorVersion uint32
+}
+
+// TypeString returns the type field as text, without trailing NUL bytes.
+func (h Header) TypeString() string {
+	return string(bytes.TrimRight(h.Type[:], "\x00"))
+}
+
+// --- Database Layout Constants ---
+
+// An InDesign file is a paged database. The first two pages are master
+// pages; each one starts with the header above and carries a commit
+// sequence number. The page with the higher sequence number is current.
+const (
+	// pageSize is the size of a database page in bytes.
+	pageSize = 4096
+	// masterPageCount is the number of master pages at the start of the file.
+	masterPageCount = 2
+	// sequenceOffset is where the 8-byte commit sequence number lives
+	// inside a master page (always little endian).
+	sequenceOffset = 264
+	// pageCountOffset is where the 4-byte database page count lives
+	// inside a master page (always little endian).
+	pageCountOffset = 280
+	// masterFieldsSize is the number of bytes of a master page we decode.
+	masterFieldsSize = pageCountOffset + 4
+)
+
+// MasterPage is one of the two database master pages.
+type MasterPage struct {
+	Header
+	// Index is the position of the page in the file (0 or 1).
+	Index int
+	// Sequence is the commit sequence number; the highest one wins.
+	Sequence uint64
+	// PageCount is the number of database pages recorded by this master.
+	PageCount uint32
+}
+
+// getInDesignVersion opens the file, reads both master pages, and returns
+// the header of the active one. It is a thin wrapper around
+// getInDesignVersionAt; use the variants below for archives, uploads and
+// other sources that are not plain files.
+func getInDesignVersion(filePath string) (Header, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return Header{}, fmt.Errorf("could not open file: %w", err)
+	}
+	defer file.Close()
+	return getInDesignVersionAt(file)
+}
+
+// getInDesignVersionAt returns the active header of the file read from r.
+func getInDesignVersionAt(r io.ReaderAt) (Header, error) {
+	pages, err := readMasterPages(r)
+	if err != nil {
+		return Header{}, err
+	}
+	return pages[activeMasterPage(pages)].Header, nil
+}
+
+// getInDesignVersionFrom returns the active header of a file read as a
+// stream (stdin, an HTTP body, a ZIP entry...). Only the master pages are
+// read from r; the rest of the stream is left unread.
+func getInDesignVersionFrom(r io.Reader) (Header, error) {
+	buf, err := readMasterPageBytes(r)
+	if err != nil {
+		return Header{}, err
+	}
+	return getInDesignVersionAt(bytes.NewReader(buf))
+}
+
+// getInDesignVersionFS returns the active header of the named file in fsys.
+func getInDesignVersionFS(fsys fs.FS, name string) (Header, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return Header{}, fmt.Errorf("could not open file: %w", err)
+	}
+	defer f.Close()
+
+	// Files that support random access (e.g. os.DirFS) skip the copy.
+	if ra, ok := f.(io.ReaderAt); ok {
+		return getInDesignVersionAt(ra)
+	}
+	return getInDesignVersionFrom(f)
+}
+
+// getMasterPages opens the file and returns every valid master page,
+// together with the index (into the returned slice) of the active one.
+func getMasterPages(filePath string) ([]MasterPage, int, error) {
 
 	// --- Step 1: Open the file for reading ---
 	file, err := os.Open(filePath)
 	if err != nil {
-		return 0, fmt.Errorf("could not open file: %w", err)
+		return nil, 0, fmt.Errorf("could not open file: %w", err)
 	}
 	// 'defer' ensures this runs right before the function exits,
 	// guaranteeing the file is closed.
 	defer file.Close()
 
-	// --- Step 2: Read the 37-byte header ---
+	// --- Step 2: Read the master pages ---
+	pages, err := readMasterPages(file)
+	if err != nil {
+		return nil, 0, err
+	}
+	return pages, activeMasterPage(pages), nil
+}
+
+// readMasterPageBytes reads up to both master pages from a stream. A short
+// stream is not an error here; readMasterPages decides what is usable.
+func readMasterPageBytes(r io.Reader) ([]byte, error) {
+	buf := make([]byte, masterPageCount*pageSize)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, fmt.Errorf("could not read header: %w", err)
+	}
+	return buf[:n], nil
+}
+
+// readMasterPages reads and decodes the master pages at the start of r.
+// The first master page must be valid; the second one is skipped when it
+// is missing or damaged, so the first page alone still identifies the file.
+func readMasterPages(r io.ReaderAt) ([]MasterPage, error) {
+	var pages []MasterPage
 
-	// Create a byte slice (a buffer) to hold the header data
-	header := make([]byte, headerSize)
+	buf := make([]byte, masterFieldsSize)
+	for i := 0; i < masterPageCount; i++ {
+		// ReadAt reports a short read as n < len(buf); only page 0 is mandatory.
+		base := int64(i) * pageSize
+		n, err := r.ReadAt(buf, base)
+		if n < len(buf) {
+			if i > 0 {
+				break
+			}
+			if err != nil && err != io.EOF {
+				return nil, fmt.Errorf("could not read header: %w", err)
+			}
+			// Parse what we have so the error names the first missing field.
+			buf = buf[:n]
+		}
 
-	// Read exactly 'headerSize' bytes from the file into our buffer
-	// We use io.ReadFull to ensure we get all 37 bytes, or an error.
-	if _, err := io.ReadFull(file, header); err != nil {
-		return 0, fmt.Errorf("could not read header: %w", err)
+		page, err := parseMasterPage(buf)
+		if err != nil {
+			if i == 0 {
+				return nil, offsetBy(err, base)
+			}
+			break
+		}
+		page.Index = i
+		pages = append(pages, page)
 	}
 
+	return pages, nil
+}
+
+// parseMasterPage decodes the header and database fields of a master page.
+// Error offsets are relative to the start of the page.
+func parseMasterPage(buf []byte) (MasterPage, error) {
+	header, err := parseHeader(buf)
+	if err != nil {
+		return MasterPage{}, err
+	}
+	if len(buf) < masterFieldsSize {
+		field := "commit sequence"
+		if len(buf) >= sequenceOffset+8 {
+			field = "page count"
+		}
+		return MasterPage{}, &ParseError{Offset: int64(len(buf)), Field: field, Err: ErrTruncated,
+			Detail: fmt.Sprintf("need %d bytes, got %d", masterFieldsSize, len(buf))}
+	}
+	return MasterPage{
+		Header:    header,
+		Sequence:  binary.LittleEndian.Uint64(buf[sequenceOffset : sequenceOffset+8]),
+		PageCount: binary.LittleEndian.Uint32(buf[pageCountOffset : pageCountOffset+4]),
+	}, nil
+}
+
+// activeMasterPage returns the index of the master page with the highest
+// commit sequence number. On a tie the first page wins.
+func activeMasterPage(pages []MasterPage) int {
+	active := 0
+	for i, p := range pages {
+		if p.Sequence > pages[active].Sequence {
+			active = i
+		}
+	}
+	return active
+}
+
+// printMasterPages writes a debug view of every master page to w.
+func printMasterPages(w io.Writer, pages []MasterPage, active int) {
+	for i, p := range pages {
+		marker := " "
+		if i == active {
+			marker = "*"
+		}
+		fmt.Fprintf(w, "%s Master page %d: type=%q (%s) endian=%d major=%d minor=%d sequence=%d pages=%d\n",
+			marker, p.Index, p.TypeString(), p.Kind, p.EndianFlag, p.MajorVersion, p.MinorVersion, p.Sequence, p.PageCount)
+	}
+}
+
+// parseHeader decodes the first headerSize bytes of an InDesign file.
+func parseHeader(header []byte) (Header, error) {
+	var h Header
+
 	// --- Step 3: Check the Magic Number (Bytes 0-15) ---
 
 	// 'header[0:16]' creates a "slice" pointing to the first 16 bytes.
-	// We compare it to our 'magicNumber' constant.
-	if !bytes.Equal(header[0:16], magicNumber) {
-		return 0, fmt.Errorf("not a valid InDesign file (magic number mismatch)")
+	// We compare it to our 'magicNumber' constant. A short file that does
+	// not even start like one is "not InDesign" rather than "truncated".
+	if !bytes.HasPrefix(magicNumber, header[:min(len(header), 16)]) {
+		return h, &ParseError{Offset: 0, Field: "magic number", Err: ErrNotInDesign}
 	}
+	if len(header) < headerSize {
+		return h, &ParseError{Offset: int64(len(header)), Field: headerFieldAt(len(header)), Err: ErrTruncated,
+			Detail: fmt.Sprintf("need %d bytes, got %d", headerSize, len(header))}
+	}
+	copy(h.Magic[:], header[0:16])
+
+	// --- Step 4: Keep the Type field (Bytes 16-23) ---
+	copy(h.Type[:], header[16:24])
+	h.Kind = kindFromType(h.TypeString())
 
-	// --- Step 4: Determine Endianness (Byte 24) ---
+	// --- Step 5: Determine Endianness (Byte 24) ---
 
 	// 'header[24]' accesses the 25th byte (index 24).
 	// We must know the endianness *before* we can read the version numbers.
-	var byteOrder binary.ByteOrder
+	h.EndianFlag = header[24]
 	// Based on the forum's JavaScript code:
 	// Flag 2 = Big Endian
-	// Flag 1 (or other) = Little Endian
-	switch header[24] {
-	case 2:
-		byteOrder = binary.BigEndian
+	// Flag 1 = Little Endian
+	switch h.EndianFlag {
+	case endianBig:
+		h.ByteOrder = binary.BigEndian
+	case endianLittle:
+		h.ByteOrder = binary.LittleEndian
 	default:
-		// Default to LittleEndian for flag 1 or any other value
-		byteOrder = binary.LittleEndian
+		return h, &ParseError{Offset: 24, Field: "endianness flag", Err: ErrUnknownEndian,
+			Detail: fmt.Sprintf("got %d, want 1 or 2", h.EndianFlag)}
 	}
 
-	// --- Step 5: Read the Major Version (Bytes 29-32) ---
+	// --- Step 6: Read the Major and Minor Versions (Bytes 29-36) ---
 
 	// 'header[29:33]' creates a slice pointing to the 4 bytes for the major version.
 	// We use Go's binary package to convert these 4 bytes into a
-	// 32-bit unsigned integer (uint32), using the 'byteOrder' we just found.
-	majorVersion := byteOrder.Uint32(header[29:33])
+	// 32-bit unsigned integer (uint32), using the byte order we just found.
+	h.MajorVersion = h.ByteOrder.Uint32(header[29:33])
+	h.MinorVersion = h.ByteOrder.Uint32(header[33:37])
+	if h.MajorVersion == 0 || h.MajorVersion > maxPlausibleMajor {
+		return h, &ParseError{Offset: 29, Field: "major version", Err: ErrImplausibleVersion,
+			Detail: fmt.Sprintf("got %d", h.MajorVersion)}
+	}
 
-	return majorVersion, nil
+	return h, nil
+}
+
+// headerFieldAt names the header field that contains byte offset off.
+func headerFieldAt(off int) string {
+	switch {
+	case off < 16:
+		return "magic number"
+	case off < 24:
+		return "type"
+	case off < 25:
+		return "endianness flag"
+	case off < 29:
+		return "padding"
+	case off < 33:
+		return "major version"
+	default:
+		return "minor version"
+	}
 }
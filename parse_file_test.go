@@ -0,0 +1,193 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"id-launcher/internal/fixture"
+)
+
+func TestGetInDesignVersion(t *testing.T) {
+	bigEndian := fixture.Document(8, 0)
+	for i := range bigEndian.Master {
+		bigEndian.Master[i].Endian = fixture.BigEndian
+	}
+
+	// The first master page is active and newer than the stale second one.
+	firstActive := fixture.Document(19, 2)
+	firstActive.Master[0].Sequence = 10
+	firstActive.Master[1].Major = 18
+
+	// A damaged second master page is ignored.
+	damagedSecond := fixture.Document(17, 0)
+	damagedSecond.Master[1].GUID = [16]byte{}
+
+	tests := []struct {
+		name         string
+		file         *fixture.File
+		kind         Kind
+		major, minor uint32
+	}{
+		{"document", fixture.Document(19, 1), KindDocument, 19, 1},
+		{"big endian", bigEndian, KindDocument, 8, 0},
+		{"book", fixture.New(fixture.TypeBook, 18, 0), KindBook, 18, 0},
+		{"library", fixture.New(fixture.TypeLibrary, 16, 0), KindLibrary, 16, 0},
+		{"first master page active", firstActive, KindDocument, 19, 2},
+		{"damaged second master page", damagedSecond, KindDocument, 17, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := getInDesignVersionAt(bytes.NewReader(tt.file.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if h.Kind != tt.kind || h.MajorVersion != tt.major || h.MinorVersion != tt.minor {
+				t.Errorf("got %s %d.%d, want %s %d.%d", h.Kind, h.MajorVersion, h.MinorVersion, tt.kind, tt.major, tt.minor)
+			}
+		})
+	}
+}
+
+func TestGetInDesignVersionErrors(t *testing.T) {
+	badEndian := fixture.Document(19, 0)
+	badEndian.Master[0].Endian = 7
+
+	tests := []struct {
+		name   string
+		data   []byte
+		err    error
+		offset int64
+	}{
+		{"empty", nil, ErrTruncated, 0},
+		{"not InDesign", []byte("%PDF-1.7 and some more bytes to fill the header"), ErrNotInDesign, 0},
+		{"truncated", fixture.Document(19, 0).Bytes()[:30], ErrTruncated, 30},
+		{"unknown endian", badEndian.Bytes(), ErrUnknownEndian, 24},
+		{"implausible major", fixture.Document(400, 0).Bytes(), ErrImplausibleVersion, 29},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := getInDesignVersionAt(bytes.NewReader(tt.data))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			var pe *ParseError
+			if !errors.As(err, &pe) || pe.Offset != tt.offset {
+				t.Errorf("got %v, want a *ParseError at byte %d", err, tt.offset)
+			}
+		})
+	}
+}
+
+func TestGetInDesignVersionFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doc.indd")
+	if err := fixture.Document(20, 0).WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	h, err := getInDesignVersion(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.MajorVersion != 20 {
+		t.Errorf("got major %d, want 20", h.MajorVersion)
+	}
+}
+
+// TestParsersFS runs the fs.FS variants of the parsers over an os-like
+// fstest.MapFS, whose files support random access, and over a zip.Reader,
+// whose files are streams.
+func TestParsersFS(t *testing.T) {
+	doc := fixture.Document(19, 2)
+	doc.XMP = fixture.Packet(fixture.XMP{CreatorTool: "Adobe InDesign 19.2 (Windows)", NPages: 4})
+
+	var idml bytes.Buffer
+	zw := zip.NewWriter(&idml)
+	w, _ := zw.Create(idmlDesignMap)
+	io.WriteString(w, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<?aid style="50" type="document" readerVersion="6.0" featureSet="257" product="18.5(57)" ?>
+<Document/>`)
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]byte{"Jobs/doc.indd": doc.Bytes(), "Jobs/doc.idml": idml.Bytes()}
+	mapFS := fstest.MapFS{}
+	var archive bytes.Buffer
+	zw = zip.NewWriter(&archive)
+	for name, data := range files {
+		mapFS[name] = &fstest.MapFile{Data: data}
+		w, _ := zw.Create(name)
+		w.Write(data)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zipFS, err := zip.NewReader(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, fsys := range map[string]fs.FS{"MapFS": mapFS, "zip": zipFS} {
+		h, err := getInDesignVersionFS(fsys, "Jobs/doc.indd")
+		if err != nil || h.MajorVersion != 19 || h.MinorVersion != 2 {
+			t.Errorf("%s: getInDesignVersionFS = %d.%d, %v; want 19.2", name, h.MajorVersion, h.MinorVersion, err)
+		}
+		md, err := getXMPMetadataFS(fsys, "Jobs/doc.indd")
+		if err != nil || md.NPages != 4 {
+			t.Errorf("%s: getXMPMetadataFS = %+v, %v; want 4 pages", name, md, err)
+		}
+		aid, err := getIDMLVersionFS(fsys, "Jobs/doc.idml")
+		if err != nil || aid.Major != 18 || aid.Minor != 5 {
+			t.Errorf("%s: getIDMLVersionFS = %+v, %v; want 18.5", name, aid, err)
+		}
+		if _, err := getInDesignVersionFS(fsys, "Jobs/missing.indd"); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s: missing file: err = %v, want fs.ErrNotExist", name, err)
+		}
+	}
+}
+
+func FuzzGetInDesignVersion(f *testing.F) {
+	f.Add(fixture.Document(19, 1).Bytes())
+	f.Add(fixture.New(fixture.TypeBook, 7, 5).Bytes())
+	big := fixture.Document(6, 0)
+	big.Master[0].Endian = fixture.BigEndian
+	f.Add(big.Bytes())
+	withXMP := fixture.Document(18, 0)
+	withXMP.XMP = fixture.Packet(fixture.XMP{CreatorTool: "Adobe InDesign 18.0 (Windows)", NPages: 2})
+	f.Add(withXMP.Bytes())
+	f.Add(fixture.Document(19, 0).Bytes()[:100])
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		h, err := getInDesignVersionAt(bytes.NewReader(data))
+		if err != nil {
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error is not a *ParseError: %v", err)
+			}
+			if pe.Offset < 0 || pe.Offset > int64(len(data)) {
+				t.Fatalf("error offset %d outside of %d-byte input", pe.Offset, len(data))
+			}
+			return
+		}
+		if h.MajorVersion == 0 || h.MajorVersion > maxPlausibleMajor {
+			t.Fatalf("accepted implausible major %d", h.MajorVersion)
+		}
+		if h.ByteOrder == nil {
+			t.Fatal("accepted header without byte order")
+		}
+		if !bytes.Equal(h.Magic[:], magicNumber) {
+			t.Fatalf("accepted magic % x", h.Magic)
+		}
+
+		// The stream variant must agree with the random-access one.
+		h2, err := getInDesignVersionFrom(bytes.NewReader(data))
+		if err != nil || h2 != h {
+			t.Fatalf("getInDesignVersionFrom = %+v, %v; want %+v", h2, err, h)
+		}
+	})
+}
@@ -0,0 +1,558 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// InDesign stores an XMP packet as a "contiguous object" after the database
+// pages. We locate it by scanning for the <x:xmpmeta> element, starting at
+// the end of the database (PageCount * pageSize of the active master page)
+// and falling back to the whole file if it is not there.
+
+// --- XMP Constants ---
+
+var (
+	xmpStartMarker = []byte("<x:xmpmeta")
+	xmpEndMarker   = []byte("</x:xmpmeta>")
+)
+
+const (
+	// xmpScanChunk is how much of the file we read at a time while scanning.
+	xmpScanChunk = 1 << 20
+	// xmpMaxPacketSize guards against runaway packets in damaged files.
+	xmpMaxPacketSize = 64 << 20
+)
+
+// XML namespaces used by the properties we decode.
+const (
+	nsRDF   = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsXMP   = "http://ns.adobe.com/xap/1.0/"
+	nsXMPMM = "http://ns.adobe.com/xap/1.0/mm/"
+	nsDC    = "http://purl.org/dc/elements/1.1/"
+	nsStEvt = "http://ns.adobe.com/xap/1.0/sType/ResourceEvent#"
+	nsGImg  = "http://ns.adobe.com/xap/1.0/g/img/"
+	nsTPg   = "http://ns.adobe.com/xap/1.0/t/pg/"
+	nsStFnt = "http://ns.adobe.com/xap/1.0/sType/Font#"
+	nsStRef = "http://ns.adobe.com/xap/1.0/sType/ResourceRef#"
+	nsStMfs = "http://ns.adobe.com/xap/1.0/sType/ManifestItem#"
+	nsStDim = "http://ns.adobe.com/xap/1.0/sType/Dimensions#"
+)
+
+// errNoXMP is returned when a file does not contain an XMP packet.
+var errNoXMP = errors.New("no XMP packet found")
+
+// XMPMetadata holds the properties we decode from a document's XMP packet.
+// Missing properties are left as zero values.
+type XMPMetadata struct {
+	// CreatorTool is the application that created the document,
+	// e.g. "Adobe InDesign 19.2 (Macintosh)".
+	CreatorTool string
+	// CreateDate, ModifyDate and MetadataDate are the xmp: dates.
+	CreateDate   time.Time
+	ModifyDate   time.Time
+	MetadataDate time.Time
+	// DocumentID, InstanceID and OriginalDocumentID are the xmpMM: identifiers.
+	DocumentID         string
+	InstanceID         string
+	OriginalDocumentID string
+	// Title is the default-language dc:title.
+	Title string
+	// Format is the dc:format MIME type.
+	Format string
+	// History lists the xmpMM:History events, oldest first.
+	History []HistoryEvent
+	// Thumbnails holds the document thumbnail (xmp:Thumbnails, Page 0)
+	// followed by any page previews (xmp:PageInfo).
+	Thumbnails []Thumbnail
+	// Fonts lists every font used by the document (xmpTPg:Fonts).
+	Fonts []FontInfo
+	// Links lists the placed files from xmpMM:Manifest and
+	// xmpMM:Ingredients, without duplicates.
+	Links []LinkedAsset
+	// NPages is the page count (xmpTPg:NPages), or 0 if not recorded.
+	NPages int
+	// MaxPageSize is the size of the largest page (xmpTPg:MaxPageSize).
+	MaxPageSize PageSize
+	// Raw is the undecoded <x:xmpmeta> packet.
+	Raw []byte
+}
+
+// HistoryEvent is one entry of xmpMM:History.
+type HistoryEvent struct {
+	// Action is what happened, e.g. "created", "saved" or "converted".
+	Action string
+	// InstanceID is the instance ID the document had after the event.
+	InstanceID string
+	// When is the time of the event.
+	When time.Time
+	// SoftwareAgent is the application that performed the event,
+	// e.g. "Adobe InDesign 19.2 (Macintosh)".
+	SoftwareAgent string
+	// Changed lists the changed parts, e.g. "/" or "/metadata".
+	Changed string
+}
+
+// Thumbnail is an embedded preview image (xmpGImg).
+type Thumbnail struct {
+	// Page is the page number for page previews, or 0 for the
+	// document thumbnail.
+	Page int
+	// Format is the image format as written, normally "JPEG".
+	Format string
+	// Width and Height are the declared pixel dimensions.
+	Width  int
+	Height int
+	// Data is the decoded image file.
+	Data []byte
+}
+
+// FontInfo is one entry of xmpTPg:Fonts.
+type FontInfo struct {
+	// Name is the PostScript name, e.g. "MinionPro-Regular".
+	Name string
+	// Family and Face are the family and style, e.g. "Minion Pro" / "Regular".
+	Family string
+	Face   string
+	// Type is the font technology, e.g. "Open Type" or "TrueType".
+	Type string
+	// Version is the font's version string.
+	Version string
+	// FileName is the font file the document was laid out with.
+	FileName string
+	// Composite is true for composite (CID) fonts.
+	Composite bool
+}
+
+// PageSize is an stDim dimensions structure.
+type PageSize struct {
+	// Width and Height are in Unit.
+	Width  float64
+	Height float64
+	// Unit is the unit as written, e.g. "Millimeters" or "inch".
+	Unit string
+}
+
+// LinkedAsset is a placed file recorded in the document's XMP.
+type LinkedAsset struct {
+	// FilePath is the original path as written, often a file:// URL.
+	FilePath string
+	// DocumentID and InstanceID identify the placed file's own XMP.
+	DocumentID string
+	InstanceID string
+	// LinkForm is the manifest link form, e.g. "ReferenceStream".
+	LinkForm string
+}
+
+// getXMPMetadata opens the file, finds its XMP packet and decodes it.
+func getXMPMetadata(filePath string) (*XMPMetadata, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("could not stat file: %w", err)
+	}
+	return getXMPMetadataAt(file, info.Size())
+}
+
+// getXMPMetadataAt finds and decodes the XMP packet of the size-byte file
+// read from r.
+func getXMPMetadataAt(r io.ReaderAt, size int64) (*XMPMetadata, error) {
+	packet, err := findXMPPacket(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return parseXMP(packet)
+}
+
+// getXMPMetadataFrom decodes the XMP packet of a file read as a stream.
+// The packet sits near the end of the file, so the whole stream is read.
+func getXMPMetadataFrom(r io.Reader) (*XMPMetadata, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not read file: %w", err)
+	}
+	return getXMPMetadataAt(bytes.NewReader(data), int64(len(data)))
+}
+
+// getXMPMetadataFS decodes the XMP packet of the named file in fsys.
+func getXMPMetadataFS(fsys fs.FS, name string) (*XMPMetadata, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file: %w", err)
+	}
+	defer f.Close()
+
+	if ra, ok := f.(io.ReaderAt); ok {
+		if info, err := f.Stat(); err == nil {
+			return getXMPMetadataAt(ra, info.Size())
+		}
+	}
+	return getXMPMetadataFrom(f)
+}
+
+// findXMPPacket returns the raw <x:xmpmeta> element from an InDesign file.
+func findXMPPacket(r io.ReaderAt, size int64) ([]byte, error) {
+	// Start right after the database pages when the master pages tell us
+	// where they end; this skips almost the whole file.
+	if pages, err := readMasterPages(r); err == nil {
+		start := int64(pages[activeMasterPage(pages)].PageCount) * pageSize
+		if start > 0 && start < size {
+			if packet, err := scanXMP(r, start, size); err == nil {
+				return packet, nil
+			}
+		}
+	}
+	return scanXMP(r, 0, size)
+}
+
+// scanXMP scans r between from and size for the first complete XMP packet.
+func scanXMP(r io.ReaderAt, from, size int64) ([]byte, error) {
+	start, err := scanFor(r, xmpStartMarker, from, size)
+	if err != nil {
+		return nil, err
+	}
+	end, err := scanFor(r, xmpEndMarker, start, min(size, start+xmpMaxPacketSize))
+	if err != nil {
+		return nil, err
+	}
+	end += int64(len(xmpEndMarker))
+
+	packet := make([]byte, end-start)
+	if _, err := r.ReadAt(packet, start); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("could not read XMP packet: %w", err)
+	}
+	return packet, nil
+}
+
+// scanFor returns the offset of the first occurrence of marker in r
+// between from and to, reading the file in chunks.
+func scanFor(r io.ReaderAt, marker []byte, from, to int64) (int64, error) {
+	buf := make([]byte, xmpScanChunk+len(marker))
+	for off := from; off < to; off += xmpScanChunk {
+		// Read a little past the chunk so a marker spanning two chunks is found.
+		n, err := r.ReadAt(buf[:min(int64(len(buf)), to-off)], off)
+		if i := bytes.Index(buf[:n], marker); i >= 0 {
+			return off + int64(i), nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, fmt.Errorf("could not scan for XMP: %w", err)
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	return 0, errNoXMP
+}
+
+// --- RDF Decoding ---
+
+// xmpMeta mirrors the <x:xmpmeta><rdf:RDF> envelope.
+type xmpMeta struct {
+	RDF struct {
+		Descriptions []rdfDescription `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# Description"`
+	} `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# RDF"`
+}
+
+// rdfDescription is one <rdf:Description>. Simple properties may be written
+// either as attributes or as child elements, so we capture both.
+type rdfDescription struct {
+	Attrs []xml.Attr `xml:",any,attr"`
+
+	CreatorTool  string `xml:"http://ns.adobe.com/xap/1.0/ CreatorTool"`
+	CreateDate   string `xml:"http://ns.adobe.com/xap/1.0/ CreateDate"`
+	ModifyDate   string `xml:"http://ns.adobe.com/xap/1.0/ ModifyDate"`
+	MetadataDate string `xml:"http://ns.adobe.com/xap/1.0/ MetadataDate"`
+
+	DocumentID         string `xml:"http://ns.adobe.com/xap/1.0/mm/ DocumentID"`
+	InstanceID         string `xml:"http://ns.adobe.com/xap/1.0/mm/ InstanceID"`
+	OriginalDocumentID string `xml:"http://ns.adobe.com/xap/1.0/mm/ OriginalDocumentID"`
+
+	Title  rdfAlt `xml:"http://purl.org/dc/elements/1.1/ title"`
+	Format string `xml:"http://purl.org/dc/elements/1.1/ format"`
+
+	History rdfList `xml:"http://ns.adobe.com/xap/1.0/mm/ History"`
+
+	Thumbnails rdfList `xml:"http://ns.adobe.com/xap/1.0/ Thumbnails"`
+	PageInfo   rdfList `xml:"http://ns.adobe.com/xap/1.0/ PageInfo"`
+
+	Fonts       rdfList `xml:"http://ns.adobe.com/xap/1.0/t/pg/ Fonts"`
+	NPages      string  `xml:"http://ns.adobe.com/xap/1.0/t/pg/ NPages"`
+	MaxPageSize rdfNode `xml:"http://ns.adobe.com/xap/1.0/t/pg/ MaxPageSize"`
+
+	Manifest    rdfList `xml:"http://ns.adobe.com/xap/1.0/mm/ Manifest"`
+	Ingredients rdfList `xml:"http://ns.adobe.com/xap/1.0/mm/ Ingredients"`
+}
+
+// rdfList is an rdf:Seq, rdf:Bag or rdf:Alt container of structured items.
+type rdfList struct {
+	Containers []struct {
+		Items []rdfNode `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# li"`
+	} `xml:",any"`
+}
+
+// items returns the list entries of every container.
+func (l rdfList) items() []rdfNode {
+	var items []rdfNode
+	for _, c := range l.Containers {
+		items = append(items, c.Items...)
+	}
+	return items
+}
+
+// rdfNode is a generic XML element, used for structured list items whose
+// fields may be written as child elements or as attributes.
+type rdfNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Text     string     `xml:",chardata"`
+	Children []rdfNode  `xml:",any"`
+}
+
+// field returns the value of a field, from a child element or an attribute.
+func (n rdfNode) field(space, local string) string {
+	if c := n.child(space, local); c != nil {
+		return strings.TrimSpace(c.Text)
+	}
+	for _, a := range n.Attrs {
+		if a.Name.Space == space && a.Name.Local == local {
+			return strings.TrimSpace(a.Value)
+		}
+	}
+	return ""
+}
+
+// resource returns the node holding a structured value's fields: the node
+// itself for rdf:parseType="Resource", or its nested rdf:Description.
+func (n rdfNode) resource() rdfNode {
+	if d := n.child(nsRDF, "Description"); d != nil {
+		return *d
+	}
+	return n
+}
+
+// child returns the first child element with the given name, or nil.
+func (n rdfNode) child(space, local string) *rdfNode {
+	for i := range n.Children {
+		if n.Children[i].XMLName.Space == space && n.Children[i].XMLName.Local == local {
+			return &n.Children[i]
+		}
+	}
+	return nil
+}
+
+// rdfAlt is a language alternative (<rdf:Alt>) property.
+type rdfAlt struct {
+	Alt struct {
+		Items []struct {
+			Lang  string `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# li"`
+	} `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# Alt"`
+}
+
+// defaultValue returns the x-default entry, or the first entry.
+func (a rdfAlt) defaultValue() string {
+	for _, item := range a.Alt.Items {
+		if item.Lang == "x-default" {
+			return strings.TrimSpace(item.Value)
+		}
+	}
+	if len(a.Alt.Items) > 0 {
+		return strings.TrimSpace(a.Alt.Items[0].Value)
+	}
+	return ""
+}
+
+// prop returns a simple property, preferring the element form and falling
+// back to the attribute form.
+func (d rdfDescription) prop(space, local, element string) string {
+	if v := strings.TrimSpace(element); v != "" {
+		return v
+	}
+	for _, a := range d.Attrs {
+		if a.Name.Space == space && a.Name.Local == local {
+			return strings.TrimSpace(a.Value)
+		}
+	}
+	return ""
+}
+
+// parseXMP decodes a raw <x:xmpmeta> packet.
+func parseXMP(packet []byte) (*XMPMetadata, error) {
+	var meta xmpMeta
+	if err := xml.Unmarshal(packet, &meta); err != nil {
+		return nil, fmt.Errorf("could not parse XMP: %w", err)
+	}
+
+	md := &XMPMetadata{Raw: packet}
+	// Properties can be spread over several rdf:Description elements;
+	// the first non-empty value wins.
+	for _, d := range meta.RDF.Descriptions {
+		setString(&md.CreatorTool, d.prop(nsXMP, "CreatorTool", d.CreatorTool))
+		setTime(&md.CreateDate, d.prop(nsXMP, "CreateDate", d.CreateDate))
+		setTime(&md.ModifyDate, d.prop(nsXMP, "ModifyDate", d.ModifyDate))
+		setTime(&md.MetadataDate, d.prop(nsXMP, "MetadataDate", d.MetadataDate))
+		setString(&md.DocumentID, d.prop(nsXMPMM, "DocumentID", d.DocumentID))
+		setString(&md.InstanceID, d.prop(nsXMPMM, "InstanceID", d.InstanceID))
+		setString(&md.OriginalDocumentID, d.prop(nsXMPMM, "OriginalDocumentID", d.OriginalDocumentID))
+		setString(&md.Title, d.Title.defaultValue())
+		setString(&md.Format, d.prop(nsDC, "format", d.Format))
+
+		if md.History == nil {
+			for _, item := range d.History.items() {
+				event := HistoryEvent{
+					Action:        item.field(nsStEvt, "action"),
+					InstanceID:    item.field(nsStEvt, "instanceID"),
+					SoftwareAgent: item.field(nsStEvt, "softwareAgent"),
+					Changed:       item.field(nsStEvt, "changed"),
+				}
+				setTime(&event.When, item.field(nsStEvt, "when"))
+				md.History = append(md.History, event)
+			}
+		}
+
+		md.Thumbnails = append(md.Thumbnails, decodeThumbnails(d.Thumbnails, false)...)
+		md.Thumbnails = append(md.Thumbnails, decodeThumbnails(d.PageInfo, true)...)
+
+		for _, item := range d.Fonts.items() {
+			md.Fonts = append(md.Fonts, FontInfo{
+				Name:      item.field(nsStFnt, "fontName"),
+				Family:    item.field(nsStFnt, "fontFamily"),
+				Face:      item.field(nsStFnt, "fontFace"),
+				Type:      item.field(nsStFnt, "fontType"),
+				Version:   item.field(nsStFnt, "versionString"),
+				FileName:  item.field(nsStFnt, "fontFileName"),
+				Composite: strings.EqualFold(item.field(nsStFnt, "composite"), "true"),
+			})
+		}
+
+		if md.NPages == 0 {
+			md.NPages, _ = strconv.Atoi(d.prop(nsTPg, "NPages", d.NPages))
+		}
+		if md.MaxPageSize.Unit == "" {
+			dim := d.MaxPageSize.resource()
+			md.MaxPageSize.Width, _ = strconv.ParseFloat(dim.field(nsStDim, "w"), 64)
+			md.MaxPageSize.Height, _ = strconv.ParseFloat(dim.field(nsStDim, "h"), 64)
+			md.MaxPageSize.Unit = dim.field(nsStDim, "unit")
+		}
+
+		for _, item := range d.Manifest.items() {
+			item = item.resource()
+			link := LinkedAsset{LinkForm: item.field(nsStMfs, "linkForm")}
+			if ref := item.child(nsStMfs, "reference"); ref != nil {
+				r := ref.resource()
+				link.FilePath = r.field(nsStRef, "filePath")
+				link.DocumentID = r.field(nsStRef, "documentID")
+				link.InstanceID = r.field(nsStRef, "instanceID")
+			}
+			md.addLink(link)
+		}
+		for _, item := range d.Ingredients.items() {
+			item = item.resource()
+			md.addLink(LinkedAsset{
+				FilePath:   item.field(nsStRef, "filePath"),
+				DocumentID: item.field(nsStRef, "documentID"),
+				InstanceID: item.field(nsStRef, "instanceID"),
+			})
+		}
+	}
+	return md, nil
+}
+
+// addLink records a linked asset unless its path is empty or already known.
+func (md *XMPMetadata) addLink(link LinkedAsset) {
+	if link.FilePath == "" {
+		return
+	}
+	for _, l := range md.Links {
+		if l.FilePath == link.FilePath {
+			return
+		}
+	}
+	md.Links = append(md.Links, link)
+}
+
+// decodeThumbnails decodes the xmpGImg items of a list. Items whose image
+// data is not valid base64 are skipped.
+func decodeThumbnails(list rdfList, pages bool) []Thumbnail {
+	var thumbs []Thumbnail
+	for i, item := range list.items() {
+		data, err := base64.StdEncoding.DecodeString(stripSpace(item.field(nsGImg, "image")))
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		t := Thumbnail{Format: item.field(nsGImg, "format"), Data: data}
+		t.Width, _ = strconv.Atoi(item.field(nsGImg, "width"))
+		t.Height, _ = strconv.Atoi(item.field(nsGImg, "height"))
+		if pages {
+			// Fall back to the list position when PageNumber is missing.
+			t.Page, err = strconv.Atoi(item.field(nsTPg, "PageNumber"))
+			if err != nil || t.Page < 1 {
+				t.Page = i + 1
+			}
+		}
+		thumbs = append(thumbs, t)
+	}
+	return thumbs
+}
+
+// stripSpace removes all white space, e.g. the line breaks inside base64 data.
+func stripSpace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func setString(dst *string, v string) {
+	if *dst == "" {
+		*dst = v
+	}
+}
+
+func setTime(dst *time.Time, v string) {
+	if dst.IsZero() {
+		if t, ok := parseXMPDate(v); ok {
+			*dst = t
+		}
+	}
+}
+
+// xmpDateLayouts are the date formats allowed by the XMP specification,
+// from most to least precise.
+var xmpDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// parseXMPDate parses an XMP date value.
+func parseXMPDate(v string) (time.Time, bool) {
+	for _, layout := range xmpDateLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"id-launcher/internal/fixture"
+)
+
+func TestGetXMPMetadata(t *testing.T) {
+	file := fixture.Document(19, 0)
+	file.XMP = fixture.Packet(fixture.XMP{
+		CreatorTool:    "Adobe InDesign 18.5 (Macintosh)",
+		ModifyDate:     "2024-03-05T10:11:12+01:00",
+		Title:          "Brochure <draft>",
+		SoftwareAgents: []string{"Adobe InDesign 18.5 (Macintosh)", "Adobe InDesign 19.2 (Windows)"},
+		NPages:         12,
+		PageWidth:      210,
+		PageHeight:     297,
+		PageUnit:       "Millimeters",
+	})
+	data := file.Bytes()
+
+	md, err := getXMPMetadataAt(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.CreatorTool != "Adobe InDesign 18.5 (Macintosh)" {
+		t.Errorf("CreatorTool = %q", md.CreatorTool)
+	}
+	if md.Title != "Brochure <draft>" {
+		t.Errorf("Title = %q", md.Title)
+	}
+	if md.ModifyDate.IsZero() {
+		t.Error("ModifyDate not parsed")
+	}
+	if md.NPages != 12 {
+		t.Errorf("NPages = %d, want 12", md.NPages)
+	}
+	if got, want := md.MaxPageSize.String(), "210 x 297 mm (8.27 x 11.69 in, 595.28 x 841.89 pt)"; got != want {
+		t.Errorf("MaxPageSize = %q, want %q", got, want)
+	}
+	agent, ok := md.lastSaveAgent()
+	if !ok || agent.Major != 19 || agent.Minor != 2 || agent.Platform != "Windows" {
+		t.Errorf("lastSaveAgent = %+v, %v; want 19.2 on Windows", agent, ok)
+	}
+}
+
+func TestGetXMPMetadataMissing(t *testing.T) {
+	data := fixture.Document(19, 0).Bytes()
+	_, err := getXMPMetadataAt(bytes.NewReader(data), int64(len(data)))
+	if !errors.Is(err, errNoXMP) {
+		t.Errorf("got error %v, want errNoXMP", err)
+	}
+}
+
+func FuzzParseXMP(f *testing.F) {
+	f.Add(fixture.Packet(fixture.XMP{}))
+	f.Add(fixture.Packet(fixture.XMP{
+		CreatorTool:    "Adobe InDesign 19.2 (Windows)",
+		CreateDate:     "2024-03-05T10:11:12+01:00",
+		DocumentID:     "xmp.did:1234",
+		Title:          "Fuzz",
+		SoftwareAgents: []string{"Adobe InDesign CS6 (Macintosh)", "Adobe InDesign 19.2 (Windows)"},
+		NPages:         3,
+		PageWidth:      8.5,
+		PageHeight:     11,
+		PageUnit:       "Inches",
+	}))
+	f.Add([]byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"></x:xmpmeta>`))
+
+	f.Fuzz(func(t *testing.T, packet []byte) {
+		md, err := parseXMP(packet)
+		if err != nil {
+			return
+		}
+		// Derived values must not panic on whatever was decoded.
+		md.lastSaveAgent()
+		_ = md.MaxPageSize.String()
+		for _, e := range md.History {
+			parseSoftwareAgent(e.SoftwareAgent)
+		}
+	})
+}
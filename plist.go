@@ -0,0 +1,409 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// Property lists come in two formats: XML, and Apple's binary "bplist00".
+// Info.plist files of installed apps are usually XML but may be binary,
+// so both are read. Values decode to map[string]any, []any, string,
+// int64, float64, bool, time.Time and []byte.
+
+// ErrNotPlist is returned when the data is neither an XML nor a binary
+// property list.
+var ErrNotPlist = errors.New("not a property list")
+
+// plistEpoch is the reference date of binary plist dates.
+var plistEpoch = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// maxPlistDepth bounds nesting, so a malicious file cannot recurse forever.
+const maxPlistDepth = 64
+
+// maxPlistObjects bounds how many objects a binary plist decodes to. Binary
+// objects can be shared, so a small file could otherwise expand
+// exponentially.
+const maxPlistObjects = 1 << 16
+
+// parsePlist decodes a property list in either format.
+func parsePlist(data []byte) (any, error) {
+	if bytes.HasPrefix(data, []byte("bplist00")) {
+		return parseBinaryPlist(data)
+	}
+	return parseXMLPlist(data)
+}
+
+// --- XML ---
+
+// parseXMLPlist decodes an XML property list.
+func parseXMLPlist(data []byte) (any, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = false
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil, ErrNotPlist
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotPlist, err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "plist" {
+			return nil, fmt.Errorf("%w: root element is <%s>", ErrNotPlist, start.Name.Local)
+		}
+		// The value is the first element inside <plist>.
+		for {
+			tok, err := d.Token()
+			if err != nil {
+				return nil, fmt.Errorf("invalid plist: %w", err)
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				return xmlPlistValue(d, t, 0)
+			case xml.EndElement:
+				return nil, fmt.Errorf("invalid plist: empty <plist>")
+			}
+		}
+	}
+}
+
+// xmlPlistValue decodes the value that starts with start.
+func xmlPlistValue(d *xml.Decoder, start xml.StartElement, depth int) (any, error) {
+	if depth > maxPlistDepth {
+		return nil, fmt.Errorf("invalid plist: nested too deeply")
+	}
+	switch start.Name.Local {
+	case "dict":
+		dict := make(map[string]any)
+		for {
+			key, done, err := xmlPlistNext(d)
+			if err != nil || done {
+				return dict, err
+			}
+			if key.Name.Local != "key" {
+				return nil, fmt.Errorf("invalid plist: <%s> where a <key> was expected", key.Name.Local)
+			}
+			var name string
+			if err := d.DecodeElement(&name, &key); err != nil {
+				return nil, fmt.Errorf("invalid plist: %w", err)
+			}
+			value, done, err := xmlPlistNext(d)
+			if err != nil {
+				return nil, err
+			}
+			if done {
+				return nil, fmt.Errorf("invalid plist: key %q has no value", name)
+			}
+			if dict[name], err = xmlPlistValue(d, value, depth+1); err != nil {
+				return nil, err
+			}
+		}
+	case "array":
+		array := []any{}
+		for {
+			elem, done, err := xmlPlistNext(d)
+			if err != nil || done {
+				return array, err
+			}
+			v, err := xmlPlistValue(d, elem, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, v)
+		}
+	case "true", "false":
+		if err := d.Skip(); err != nil {
+			return nil, fmt.Errorf("invalid plist: %w", err)
+		}
+		return start.Name.Local == "true", nil
+	}
+
+	var text string
+	if err := d.DecodeElement(&text, &start); err != nil {
+		return nil, fmt.Errorf("invalid plist: %w", err)
+	}
+	switch start.Name.Local {
+	case "string":
+		return text, nil
+	case "integer":
+		n, err := strconv.ParseInt(strings.TrimSpace(text), 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid plist integer %q", text)
+		}
+		return n, nil
+	case "real":
+		f, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid plist real %q", text)
+		}
+		return f, nil
+	case "date":
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("invalid plist date %q", text)
+		}
+		return t, nil
+	case "data":
+		b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid plist data: %w", err)
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("invalid plist: unknown element <%s>", start.Name.Local)
+	}
+}
+
+// xmlPlistNext returns the next child element, or done at the end of the
+// enclosing element.
+func xmlPlistNext(d *xml.Decoder) (start xml.StartElement, done bool, err error) {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return start, false, fmt.Errorf("invalid plist: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			return t, false, nil
+		case xml.EndElement:
+			return start, true, nil
+		}
+	}
+}
+
+// --- Binary ---
+
+// binaryPlist holds a binary property list being decoded.
+type binaryPlist struct {
+	data    []byte
+	offsets []uint64
+	refSize int
+	decoded int
+}
+
+// parseBinaryPlist decodes a "bplist00" property list. The layout is a
+// header, the objects, an offset table and a 32-byte trailer that tells
+// where everything is.
+func parseBinaryPlist(data []byte) (any, error) {
+	const headerSize, trailerSize = 8, 32
+	if len(data) < headerSize+trailerSize {
+		return nil, fmt.Errorf("invalid binary plist: only %d bytes", len(data))
+	}
+	trailer := data[len(data)-trailerSize:]
+	offsetSize := int(trailer[6])
+	refSize := int(trailer[7])
+	numObjects := binary.BigEndian.Uint64(trailer[8:])
+	top := binary.BigEndian.Uint64(trailer[16:])
+	tableOffset := binary.BigEndian.Uint64(trailer[24:])
+
+	end := uint64(len(data) - trailerSize)
+	if offsetSize < 1 || offsetSize > 8 || refSize < 1 || refSize > 8 {
+		return nil, fmt.Errorf("invalid binary plist: offset size %d, reference size %d", offsetSize, refSize)
+	}
+	if numObjects == 0 || top >= numObjects || tableOffset < headerSize || tableOffset > end ||
+		numObjects > (end-tableOffset)/uint64(offsetSize) {
+		return nil, fmt.Errorf("invalid binary plist: bad trailer")
+	}
+
+	p := &binaryPlist{data: data[:end], refSize: refSize}
+	p.offsets = make([]uint64, numObjects)
+	for i := range p.offsets {
+		start := tableOffset + uint64(i*offsetSize)
+		p.offsets[i] = beUint(data[start : start+uint64(offsetSize)])
+	}
+	return p.object(top, 0)
+}
+
+// beUint decodes a big-endian unsigned integer of up to 8 bytes.
+func beUint(b []byte) uint64 {
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n
+}
+
+// object decodes object number ref.
+func (p *binaryPlist) object(ref uint64, depth int) (any, error) {
+	if depth > maxPlistDepth {
+		return nil, fmt.Errorf("invalid binary plist: nested too deeply")
+	}
+	if p.decoded++; p.decoded > maxPlistObjects {
+		return nil, fmt.Errorf("invalid binary plist: more than %d objects", maxPlistObjects)
+	}
+	if ref >= uint64(len(p.offsets)) {
+		return nil, fmt.Errorf("invalid binary plist: object %d does not exist", ref)
+	}
+	off := p.offsets[ref]
+	if off >= uint64(len(p.data)) {
+		return nil, fmt.Errorf("invalid binary plist: object %d is out of range", ref)
+	}
+	marker := p.data[off]
+	kind, info := marker>>4, marker&0x0f
+	pos := off + 1
+
+	switch kind {
+	case 0x0:
+		switch info {
+		case 0x8:
+			return false, nil
+		case 0x9:
+			return true, nil
+		}
+		return nil, nil
+	case 0x1: // integer of 2^info bytes
+		b, err := p.bytes(pos, 1<<info)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) > 8 {
+			b = b[len(b)-8:] // 128-bit integers: keep the low 64 bits
+		}
+		if len(b) == 8 {
+			return int64(binary.BigEndian.Uint64(b)), nil
+		}
+		return int64(beUint(b)), nil
+	case 0x2: // real of 2^info bytes
+		b, err := p.bytes(pos, 1<<info)
+		if err != nil {
+			return nil, err
+		}
+		switch len(b) {
+		case 4:
+			return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+		case 8:
+			return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+		}
+		return nil, fmt.Errorf("invalid binary plist: %d-byte real", len(b))
+	case 0x3: // date: seconds since 2001-01-01
+		b, err := p.bytes(pos, 8)
+		if err != nil {
+			return nil, err
+		}
+		secs := math.Float64frombits(binary.BigEndian.Uint64(b))
+		return plistEpoch.Add(time.Duration(secs * float64(time.Second))), nil
+	case 0x8: // UID of info+1 bytes, only used by keyed archives
+		b, err := p.bytes(pos, uint64(info)+1)
+		if err != nil {
+			return nil, err
+		}
+		return int64(beUint(b)), nil
+	}
+
+	// The remaining kinds have a length: info, or an integer object that
+	// follows the marker when info is 0xf.
+	n := uint64(info)
+	if info == 0x0f {
+		if pos >= uint64(len(p.data)) || p.data[pos]>>4 != 0x1 {
+			return nil, fmt.Errorf("invalid binary plist: bad length of object %d", ref)
+		}
+		size := uint64(1) << (p.data[pos] & 0x0f)
+		b, err := p.bytes(pos+1, size)
+		if err != nil {
+			return nil, err
+		}
+		n = beUint(b)
+		pos += 1 + size
+	}
+
+	switch kind {
+	case 0x4: // data
+		b, err := p.bytes(pos, n)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	case 0x5: // ASCII string
+		b, err := p.bytes(pos, n)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case 0x6: // UTF-16BE string, n code units
+		if n > uint64(len(p.data)) {
+			return nil, fmt.Errorf("invalid binary plist: string of object %d is out of range", ref)
+		}
+		b, err := p.bytes(pos, 2*n)
+		if err != nil {
+			return nil, err
+		}
+		u := make([]uint16, n)
+		for i := range u {
+			u[i] = binary.BigEndian.Uint16(b[2*i:])
+		}
+		return string(utf16.Decode(u)), nil
+	case 0xa: // array
+		refs, err := p.refs(pos, n)
+		if err != nil {
+			return nil, err
+		}
+		array := make([]any, len(refs))
+		for i, r := range refs {
+			if array[i], err = p.object(r, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return array, nil
+	case 0xd: // dict: n key references, then n value references
+		if n > uint64(len(p.data))/2 {
+			return nil, fmt.Errorf("invalid binary plist: dict of object %d is out of range", ref)
+		}
+		refs, err := p.refs(pos, 2*n)
+		if err != nil {
+			return nil, err
+		}
+		dict := make(map[string]any, n)
+		for i := uint64(0); i < n; i++ {
+			k, err := p.object(refs[i], depth+1)
+			if err != nil {
+				return nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid binary plist: dict key of type %T", k)
+			}
+			if dict[key], err = p.object(refs[n+i], depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return dict, nil
+	}
+	return nil, fmt.Errorf("invalid binary plist: unknown object type 0x%02x", marker)
+}
+
+// bytes returns n bytes at pos, or an error if they are out of range.
+func (p *binaryPlist) bytes(pos, n uint64) ([]byte, error) {
+	if pos > uint64(len(p.data)) || n > uint64(len(p.data))-pos {
+		return nil, fmt.Errorf("invalid binary plist: %d bytes at %d are out of range", n, pos)
+	}
+	return p.data[pos : pos+n], nil
+}
+
+// refs returns n object references at pos.
+func (p *binaryPlist) refs(pos, n uint64) ([]uint64, error) {
+	if n > uint64(len(p.data)) {
+		return nil, fmt.Errorf("invalid binary plist: %d references are out of range", n)
+	}
+	b, err := p.bytes(pos, n*uint64(p.refSize))
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]uint64, n)
+	for i := range refs {
+		refs[i] = beUint(b[i*p.refSize : (i+1)*p.refSize])
+	}
+	return refs, nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"id-launcher/internal/fixture"
+)
+
+// infoPlist is what the tests expect both formats to decode to.
+var infoPlist = map[string]any{
+	"CFBundleIdentifier":         "com.adobe.InDesign",
+	"CFBundleShortVersionString": "19.2",
+	"CFBundleExecutable":         "Adobe InDesign 2024",
+	"CFBundleDocumentTypes": []any{
+		map[string]any{"CFBundleTypeExtensions": []any{"indd", "indt"}, "LSIsAppleDefaultForType": true},
+	},
+	"NSHumanReadableCopyright": "© 2023 Adobe Inc. All rights reserved, and a long string.",
+	"Build":                    int64(413),
+	"Ratio":                    1.5,
+	"Blob":                     []byte{0, 1, 2},
+}
+
+const infoPlistXML = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>CFBundleIdentifier</key>
+	<string>com.adobe.InDesign</string>
+	<key>CFBundleShortVersionString</key>
+	<string>19.2</string>
+	<key>CFBundleExecutable</key>
+	<string>Adobe InDesign 2024</string>
+	<key>CFBundleDocumentTypes</key>
+	<array>
+		<dict>
+			<key>CFBundleTypeExtensions</key>
+			<array><string>indd</string><string>indt</string></array>
+			<key>LSIsAppleDefaultForType</key>
+			<true/>
+		</dict>
+	</array>
+	<key>NSHumanReadableCopyright</key>
+	<string>© 2023 Adobe Inc. All rights reserved, and a long string.</string>
+	<key>Build</key>
+	<integer>413</integer>
+	<key>Ratio</key>
+	<real>1.5</real>
+	<key>Blob</key>
+	<data>AAEC</data>
+</dict>
+</plist>
+`
+
+func TestParsePlist(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"xml":    []byte(infoPlistXML),
+		"binary": fixture.BinaryPlist(infoPlist),
+	} {
+		got, err := parsePlist(data)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, infoPlist) {
+			t.Errorf("%s: got %#v\nwant %#v", name, got, infoPlist)
+		}
+	}
+}
+
+func TestParsePlistErrors(t *testing.T) {
+	binary := fixture.BinaryPlist(infoPlist)
+	for name, data := range map[string][]byte{
+		"empty":          nil,
+		"html":           []byte("<html><body/></html>"),
+		"unknown type":   []byte("<plist><foo/></plist>"),
+		"bad integer":    []byte("<plist><integer>x</integer></plist>"),
+		"key only":       []byte("<plist><dict><key>a</key></dict></plist>"),
+		"short binary":   []byte("bplist00"),
+		"truncated":      binary[:len(binary)-40],
+		"bad trailer":    append(bytes.Clone(binary[:len(binary)-8]), 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff),
+		"garbage binary": append([]byte("bplist00"), make([]byte, 40)...),
+		// A dict claiming 1<<63 entries, whose reference count overflows.
+		"huge dict": append([]byte("bplist00\xdf\x13\x80\x00\x00\x00\x00\x00\x00\x00\x08"),
+			append(make([]byte, 6), 1, 1, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 18)...),
+	} {
+		if v, err := parsePlist(data); err == nil {
+			t.Errorf("%s: parsed as %#v, want an error", name, v)
+		}
+	}
+	if _, err := parsePlist([]byte("hello")); !errors.Is(err, ErrNotPlist) {
+		t.Errorf("plain text: err = %v, want ErrNotPlist", err)
+	}
+}
+
+func TestBundleDiscoverer(t *testing.T) {
+	info := func(id, version, exe string) *fstest.MapFile {
+		return &fstest.MapFile{Data: fixture.BinaryPlist(map[string]any{
+			"CFBundleIdentifier": id, "CFBundleShortVersionString": version, "CFBundleExecutable": exe,
+		})}
+	}
+	fsys := fstest.MapFS{
+		// Standard layout, XML Info.plist.
+		"Applications/Adobe InDesign 2024/Adobe InDesign 2024.app/Contents/Info.plist":                {Data: []byte(infoPlistXML)},
+		"Applications/Adobe InDesign 2024/Adobe InDesign 2024.app/Contents/MacOS/Adobe InDesign 2024": {},
+		// Renamed folder and app: found by the bundle, not the name.
+		"Applications/Design Tools/InDesign Old.app/Contents/Info.plist":                   info("com.adobe.InDesign", "14.0.3", "Adobe InDesign CC 2019"),
+		"Applications/Design Tools/InDesign Old.app/Contents/MacOS/Adobe InDesign CC 2019": {},
+		// Localized folder name, version with a build number.
+		"Applications/Adobe InDesign CS6 (Deutsch)/Adobe InDesign CS6.app/Contents/Info.plist":               info("com.adobe.InDesign", "8.0.2.413", "Adobe InDesign CS6"),
+		"Applications/Adobe InDesign CS6 (Deutsch)/Adobe InDesign CS6.app/Contents/MacOS/Adobe InDesign CS6": {},
+		// Named like InDesign, but something else.
+		"Applications/Adobe InDesign 2025/Adobe InDesign 2025.app/Contents/Info.plist":      info("com.adobe.InDesign.Uninstaller", "20.0", "Uninstall"),
+		"Applications/Adobe InDesign 2025/Adobe InDesign 2025.app/Contents/MacOS/Uninstall": {},
+		// Half-removed install whose binary is gone.
+		"Applications/Adobe InDesign 2023/Adobe InDesign 2023.app/Contents/Info.plist": info("com.adobe.InDesign", "18.5", "Adobe InDesign 2023"),
+		// Other apps.
+		"Applications/Safari.app/Contents/Info.plist": info("com.apple.Safari", "17.0", "Safari"),
+		"Applications/Broken.app/Contents/Info.plist": {Data: []byte("not a plist")},
+	}
+	found, err := discoverAll([]Discoverer{bundleDiscoverer{fsys: fsys, dir: "Applications"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[uint32]string{
+		8:  "Adobe InDesign CS6.app",
+		14: "InDesign Old.app",
+		19: "Adobe InDesign 2024.app",
+	}
+	if len(found) != len(want) {
+		t.Errorf("found %v, want majors 8, 14 and 19", found)
+	}
+	for major, app := range want {
+		if !strings.HasSuffix(found[major], app) {
+			t.Errorf("major %d = %q, want %s", major, found[major], app)
+		}
+	}
+
+	// A missing folder means nothing found, like folderScanDiscoverer.
+	if installs, err := (bundleDiscoverer{fsys: fstest.MapFS{}, dir: "Applications"}).Discover(); err != nil || len(installs) != 0 {
+		t.Errorf("missing folder: found %v, err %v", installs, err)
+	}
+}
+
+func FuzzParsePlist(f *testing.F) {
+	f.Add([]byte(infoPlistXML))
+	f.Add(fixture.BinaryPlist(infoPlist))
+	f.Add(fixture.BinaryPlist([]any{[]any{[]any{}}, map[string]any{}}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Must not panic or hang.
+		parsePlist(data)
+	})
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// launchPolicy decides which installed version selectVersionToLaunch picks.
+type launchPolicy int
+
+const (
+	// policyOldestCompatible picks the oldest installed version that can
+	// open the file, falling back to the newest one. This avoids
+	// needless conversions and is the default for .indd files.
+	policyOldestCompatible launchPolicy = iota
+	// policyNewest always picks the newest installed version. Used for
+	// forward-compatible formats such as IDML.
+	policyNewest
+	// policyExact only accepts the version the file was saved with and
+	// refuses to launch otherwise. The default for shared templates, so
+	// nobody upgrades them by accident.
+	policyExact
+	// policyReadOnlyCopy picks like policyOldestCompatible, but when the
+	// chosen version is newer than the file (and would convert it), a
+	// read-only copy is opened instead. The default for shared libraries.
+	policyReadOnlyCopy
+)
+
+// launchPolicies lists every policy, in the order shown in help texts.
+var launchPolicies = []launchPolicy{policyOldestCompatible, policyNewest, policyExact, policyReadOnlyCopy}
+
+// String returns the policy name.
+func (p launchPolicy) String() string {
+	switch p {
+	case policyOldestCompatible:
+		return "oldest-compatible"
+	case policyNewest:
+		return "newest"
+	case policyExact:
+		return "exact"
+	case policyReadOnlyCopy:
+		return "read-only-copy"
+	default:
+		return fmt.Sprintf("launchPolicy(%d)", int(p))
+	}
+}
+
+// parseLaunchPolicy returns the policy with the given name.
+func parseLaunchPolicy(name string) (launchPolicy, error) {
+	var names []string
+	for _, p := range launchPolicies {
+		if strings.EqualFold(name, p.String()) {
+			return p, nil
+		}
+		names = append(names, p.String())
+	}
+	return 0, fmt.Errorf("unknown launch policy %q (want one of: %s)", name, strings.Join(names, ", "))
+}
+
+// policyForKind returns the policy configured for a file kind. Documents,
+// books and unknown kinds use fallback.
+func policyForKind(kind Kind, opts launchOptions, fallback launchPolicy) launchPolicy {
+	switch kind {
+	case KindTemplate:
+		return opts.templatePolicy
+	case KindLibrary:
+		return opts.libraryPolicy
+	default:
+		return fallback
+	}
+}
+
+// launchTarget is what openFile learns about a file before it picks a
+// version: the version the file needs, how to choose, and any warnings.
+type launchTarget struct {
+	version  Version
+	policy   launchPolicy
+	warnings []string
+}
+
+// warn prints a warning right away and keeps it for the launch
+// notification. Empty warnings are ignored.
+func (t *launchTarget) warn(w string) {
+	if w == "" {
+		return
+	}
+	fmt.Printf("... WARNING: %s\n", w)
+	t.warnings = append(t.warnings, w)
+}
+
+// makeReadOnlyCopy copies path into a new temporary folder and makes the
+// copy read-only, so opening it in a newer version cannot convert the
+// original. It returns the path of the copy.
+func makeReadOnlyCopy(path string) (string, error) {
+	copyPath, err := copyToTemp(path)
+	if err != nil {
+		return "", err
+	}
+	if err := os.Chmod(copyPath, 0444); err != nil {
+		return "", fmt.Errorf("could not make copy read-only: %w", err)
+	}
+	return copyPath, nil
+}
+
+// tempCopyMaxAge is how long copies are kept. Older ones are removed the
+// next time a copy is made; by then InDesign has long closed them.
+const tempCopyMaxAge = 7 * 24 * time.Hour
+
+// tempCopyRoot is where copies accumulate, one folder per copy, e.g.
+// /tmp/indesign-launcher or %TEMP%\indesign-launcher.
+func tempCopyRoot() string {
+	return filepath.Join(os.TempDir(), "indesign-launcher")
+}
+
+// copyToTemp copies path into a new folder under tempCopyRoot, keeping its
+// name, and returns the path of the copy. Copies older than tempCopyMaxAge
+// are pruned first.
+func copyToTemp(path string) (string, error) {
+	root := tempCopyRoot()
+	pruneTempCopies(root, tempCopyMaxAge, time.Now())
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return "", fmt.Errorf("could not create temporary folder: %w", err)
+	}
+	dir, err := os.MkdirTemp(root, "copy-")
+	if err != nil {
+		return "", fmt.Errorf("could not create temporary folder: %w", err)
+	}
+	copyPath := filepath.Join(dir, filepath.Base(path))
+
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	dst, err := os.Create(copyPath)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return "", fmt.Errorf("could not copy file: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		return "", err
+	}
+	return copyPath, nil
+}
+
+// pruneTempCopies removes the copy folders in root that are older than
+// maxAge. Failures are ignored: a copy that is still open is simply
+// retried next time.
+func pruneTempCopies(root string, maxAge time.Duration, now time.Time) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !e.IsDir() || now.Sub(info.ModTime()) < maxAge {
+			continue
+		}
+		dir := filepath.Join(root, e.Name())
+		// Read-only copies cannot be deleted on Windows until they are
+		// writable again.
+		filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				os.Chmod(path, 0644)
+			}
+			return nil
+		})
+		os.RemoveAll(dir)
+	}
+}
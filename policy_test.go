@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCopyToTempPrunesOldCopies(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	doc := filepath.Join(t.TempDir(), "Library.indl")
+	if err := os.WriteFile(doc, []byte("library"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	old, err := makeReadOnlyCopy(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(filepath.Dir(old)) != tempCopyRoot() {
+		t.Errorf("copy %s is not under %s", old, tempCopyRoot())
+	}
+	if info, err := os.Stat(old); err != nil || info.Mode().Perm()&0222 != 0 {
+		t.Errorf("copy is not read-only: %v, %v", info, err)
+	}
+	longAgo := time.Now().Add(-2 * tempCopyMaxAge)
+	if err := os.Chtimes(filepath.Dir(old), longAgo, longAgo); err != nil {
+		t.Fatal(err)
+	}
+
+	recent, err := copyToTemp(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Dir(old)); !os.IsNotExist(err) {
+		t.Errorf("old copy was not pruned: %v", err)
+	}
+	if data, err := os.ReadFile(recent); err != nil || string(data) != "library" {
+		t.Errorf("new copy = %q, %v", data, err)
+	}
+}
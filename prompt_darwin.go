@@ -0,0 +1,29 @@
+//go:build darwin
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// askLockAction shows a dialog asking what to do with a document that is
+// already open elsewhere. Closing the dialog aborts.
+func askLockAction(message string) lockAction {
+	script := fmt.Sprintf(`display dialog %q with title "InDesign Launcher" `+
+		`buttons {"Abort", "Open a Copy", "Open Anyway"} default button "Abort" with icon caution`,
+		message+"\n\nOpening it anyway may overwrite their changes.")
+	out, err := exec.Command("osascript", "-e", script).Output()
+	if err != nil {
+		return lockAbort
+	}
+	switch strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(out)), "button returned:")) {
+	case "Open a Copy":
+		return lockCopy
+	case "Open Anyway":
+		return lockContinue
+	default:
+		return lockAbort
+	}
+}
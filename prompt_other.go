@@ -0,0 +1,25 @@
+//go:build !windows && !darwin
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// askLockAction asks on the terminal what to do with a document that is
+// already open elsewhere. Anything but "c" or "o" aborts.
+func askLockAction(message string) lockAction {
+	fmt.Printf("%s\n[a]bort, open a [c]opy, or [o]pen anyway? ", message)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "c", "copy":
+		return lockCopy
+	case "o", "open":
+		return lockContinue
+	default:
+		return lockAbort
+	}
+}
@@ -0,0 +1,39 @@
+//go:build windows
+
+package main
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// MessageBox result codes; golang.org/x/sys/windows only exports the
+// MB_* flags.
+const (
+	IDYES = 6
+	IDNO  = 7
+)
+
+// askLockAction shows a message box asking what to do with a document that
+// is already open elsewhere. Closing the box aborts.
+func askLockAction(message string) lockAction {
+	text := message + "\n\nOpening it anyway may overwrite their changes.\n\n" +
+		"Yes: open a copy\nNo: open anyway\nCancel: do not open"
+	textPtr, err := windows.UTF16PtrFromString(text)
+	if err != nil {
+		return lockAbort
+	}
+	titlePtr, _ := windows.UTF16PtrFromString("InDesign Launcher")
+
+	ret, err := windows.MessageBox(0, textPtr, titlePtr, windows.MB_YESNOCANCEL|windows.MB_ICONWARNING|windows.MB_DEFBUTTON3)
+	if err != nil {
+		return lockAbort
+	}
+	switch ret {
+	case IDYES:
+		return lockCopy
+	case IDNO:
+		return lockContinue
+	default:
+		return lockAbort
+	}
+}
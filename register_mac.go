@@ -213,6 +213,13 @@ func buildInfoPlist(exeName, shortVer, buildVer string) string {
 			<key>CFBundleTypeExtensions</key>
 			<array>
 				<string>indd</string>
+				<string>indb</string>
+				<string>indt</string>
+				<string>indl</string>
+				<string>idml</string>
+				<string>icml</string>
+				<string>idms</string>
+				<string>inx</string>
 			</array>
 			<key>LSItemContentTypes</key>
 			<array>
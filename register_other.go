@@ -0,0 +1,18 @@
+//go:build !windows && !darwin
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// RegisterHandler is not supported on this operating system.
+func RegisterHandler() error {
+	return fmt.Errorf("registration is not supported on %s", runtime.GOOS)
+}
+
+// UnregisterHandler is not supported on this operating system.
+func UnregisterHandler() error {
+	return fmt.Errorf("registration is not supported on %s", runtime.GOOS)
+}
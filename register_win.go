@@ -15,12 +15,16 @@ const (
 	// Our new Program ID
 	progID = "InDesignLauncher.indd"
 
-	// The key for the "Open With" list
-	openWithKeyPath = `Software\Microsoft\Windows\CurrentVersion\Explorer\FileExts\.indd\OpenWithProgids`
+	// The key for the "Open With" list of one extension
+	openWithKeyPathFormat = `Software\Microsoft\Windows\CurrentVersion\Explorer\FileExts\%s\OpenWithProgids`
 )
 
 var progIDKeyPath = fmt.Sprintf(`Software\Classes\%s`, progID)
 
+// handledExtensions are the file types we register for, the same set as
+// CFBundleTypeExtensions in register_mac.go.
+var handledExtensions = []string{".indd", ".indb", ".indt", ".indl", ".idml", ".icml", ".idms", ".inx"}
+
 // --- Windows API Constants for SHChangeNotify ---
 const (
 	SHCNE_ASSOCCHANGED = 0x08000000
@@ -56,17 +60,11 @@ func RegisterHandler() error {
 	}
 	cmdKey.Close()
 
-	// 3. Add entry to the OpenWithProgids list
-	key, _, err := registry.CreateKey(registry.CURRENT_USER, openWithKeyPath, registry.SET_VALUE)
-	if err != nil {
-		return fmt.Errorf("could not create/open OpenWithProgids key: %w", err)
-	}
-	defer key.Close()
-
-	// Set our value. We use SetValue with REG_NONE.
-	// We just need to create an empty byte slice for the data.
-	if err := key.SetBinaryValue(progID, []byte{}); err != nil {
-		return fmt.Errorf("could not add entry to OpenWithProgids: %w", err)
+	// 3. Add entry to the OpenWithProgids list of every extension
+	for _, ext := range handledExtensions {
+		if err := addOpenWith(ext); err != nil {
+			return err
+		}
 	}
 
 	// 4. Notify the Windows Shell of the change
@@ -75,7 +73,7 @@ func RegisterHandler() error {
 	// 5. Inform the user as requested
 	fmt.Println("Successfully added 'InDesign Launcher' to the 'Open With' list.")
 	fmt.Println("To set as default:")
-	fmt.Println("  1. Right-click an .indd file")
+	fmt.Println("  1. Right-click an .indd file (or any other InDesign file type)")
 	fmt.Println("  2. Select 'Open with' > 'Choose another app'")
 	fmt.Println("  3. Select 'InDesign Launcher' and check 'Always use this app...'")
 	return nil
@@ -85,16 +83,18 @@ func RegisterHandler() error {
 func UnregisterHandler() error {
 	var warnings []string
 
-	// 1. Remove from OpenWithProgids
-	key, err := registry.OpenKey(registry.CURRENT_USER, openWithKeyPath, registry.SET_VALUE)
-	if err == nil {
-		// Key exists, now delete our value
-		if err := key.DeleteValue(progID); err != nil && err != registry.ErrNotExist {
-			warnings = append(warnings, fmt.Sprintf("could not delete OpenWithProgids value: %v", err))
+	// 1. Remove from OpenWithProgids of every extension
+	for _, ext := range handledExtensions {
+		key, err := registry.OpenKey(registry.CURRENT_USER, fmt.Sprintf(openWithKeyPathFormat, ext), registry.SET_VALUE)
+		if err == nil {
+			// Key exists, now delete our value
+			if err := key.DeleteValue(progID); err != nil && err != registry.ErrNotExist {
+				warnings = append(warnings, fmt.Sprintf("could not delete %s OpenWithProgids value: %v", ext, err))
+			}
+			key.Close()
+		} else if err != registry.ErrNotExist {
+			warnings = append(warnings, fmt.Sprintf("could not open %s OpenWithProgids key: %v", ext, err))
 		}
-		key.Close()
-	} else if err != registry.ErrNotExist {
-		warnings = append(warnings, fmt.Sprintf("could not open OpenWithProgids key: %v", err))
 	}
 
 	// 2. Delete our ProgID
@@ -127,6 +127,22 @@ func UnregisterHandler() error {
 	return nil
 }
 
+// addOpenWith adds our ProgID to the OpenWithProgids list of ext.
+func addOpenWith(ext string) error {
+	key, _, err := registry.CreateKey(registry.CURRENT_USER, fmt.Sprintf(openWithKeyPathFormat, ext), registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("could not create/open %s OpenWithProgids key: %w", ext, err)
+	}
+	defer key.Close()
+
+	// Set our value. We use SetValue with REG_NONE.
+	// We just need to create an empty byte slice for the data.
+	if err := key.SetBinaryValue(progID, []byte{}); err != nil {
+		return fmt.Errorf("could not add entry to %s OpenWithProgids: %w", ext, err)
+	}
+	return nil
+}
+
 // notifyWindowsShell tells Explorer that file associations have changed.
 func notifyWindowsShell() {
 	shChangeNotifyProc.Call(SHCNE_ASSOCCHANGED, SHCNF_IDLIST, 0, 0)
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// xmlFormats maps the extensions of InDesign's XML formats to a description.
+var xmlFormats = map[string]string{
+	".icml": "InCopy story",
+	".idms": "InDesign snippet",
+	".inx":  "InDesign interchange",
+}
+
+// isXMLFormatFile reports whether filePath has the extension of one of the
+// XML formats in xmlFormats.
+func isXMLFormatFile(filePath string) bool {
+	_, ok := xmlFormats[strings.ToLower(filepath.Ext(filePath))]
+	return ok
+}
+
+// sniffXMLFile opens an XML file and returns its <?aid?> instruction.
+func sniffXMLFile(filePath string) (AIDInstruction, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return AIDInstruction{}, fmt.Errorf("could not open file: %w", err)
+	}
+	defer f.Close()
+	return readAIDInstruction(f)
+}
+
+// describeXMLFile reads the version of an InCopy story, snippet or INX
+// file and prints it. These follow the same policy as .indd files.
+func describeXMLFile(absPath string) (launchTarget, error) {
+	aid, err := sniffXMLFile(absPath)
+	if err != nil {
+		return launchTarget{}, err
+	}
+	fmt.Printf("Detected File Kind: %s\n", xmlFormats[strings.ToLower(filepath.Ext(absPath))])
+	version := Version{Major: aid.Major, Minor: aid.Minor}
+	fmt.Printf("Detected File Version: %s (Major: %d, Minor: %d, Product: %s)\n", version.Name(), aid.Major, aid.Minor, aid.Product)
+	return launchTarget{version: version, policy: policyOldestCompatible}, nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// jpegQuality is used when a preview has to be re-encoded as JPEG.
+const jpegQuality = 90
+
+// runThumbnail implements the "thumbnail" command: it decodes an embedded
+// XMP preview and writes it as JPEG or PNG, optionally resized. It only
+// reads the file, so it works anywhere, with or without InDesign.
+func runThumbnail(args []string) error {
+	fs := flag.NewFlagSet("thumbnail", flag.ExitOnError)
+	outFlag := fs.String("o", "", "Output file (.jpg or .png); defaults to <file>.jpg, which is never overwritten")
+	sizeFlag := fs.Int("size", 0, "Fit the preview into an N x N pixel box (0 = original size)")
+	pageFlag := fs.Int("page", 0, "Page preview to extract (0 = document thumbnail)")
+	positional, err := parseCommandFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: %s", commands["thumbnail"].usage)
+	}
+	filePath := positional[0]
+
+	md, err := getXMPMetadata(filePath)
+	if err != nil {
+		return fmt.Errorf("error reading XMP from '%s': %w", filePath, err)
+	}
+	thumb, ok := selectThumbnail(md.Thumbnails, *pageFlag)
+	if !ok {
+		if *pageFlag > 0 {
+			return fmt.Errorf("'%s' has no preview for page %d", filePath, *pageFlag)
+		}
+		return fmt.Errorf("'%s' has no embedded preview", filePath)
+	}
+
+	outPath := *outFlag
+	if outPath == "" {
+		outPath = strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ".jpg"
+	}
+
+	data, err := encodeThumbnail(thumb, strings.ToLower(filepath.Ext(outPath)), *sizeFlag)
+	if err != nil {
+		return err
+	}
+	if err := writeThumbnailFile(outPath, data, *outFlag != ""); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s\n", outPath)
+	return nil
+}
+
+// writeThumbnailFile writes data to path. An existing file is only
+// replaced when overwrite is set, i.e. when -o named it explicitly; the
+// default <file>.jpg next to a document may be someone's own image.
+func writeThumbnailFile(path string, data []byte, overwrite bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	}
+	f, err := os.OpenFile(path, flags, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("'%s' already exists; name it with -o to overwrite it", path)
+	}
+	if err != nil {
+		return fmt.Errorf("could not write thumbnail: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("could not write thumbnail: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("could not write thumbnail: %w", err)
+	}
+	return nil
+}
+
+// selectThumbnail picks the preview for a page, or for page 0 the document
+// thumbnail (falling back to the first page preview).
+func selectThumbnail(thumbs []Thumbnail, page int) (Thumbnail, bool) {
+	for _, t := range thumbs {
+		if t.Page == page {
+			return t, true
+		}
+	}
+	if page == 0 && len(thumbs) > 0 {
+		return thumbs[0], true
+	}
+	return Thumbnail{}, false
+}
+
+// encodeThumbnail converts a preview to the format implied by ext,
+// resizing it to fit a size x size box when size > 0.
+func encodeThumbnail(thumb Thumbnail, ext string, size int) ([]byte, error) {
+	isJPEG := strings.EqualFold(thumb.Format, "JPEG")
+
+	// Nothing to convert: hand back the embedded bytes untouched.
+	if size <= 0 && isJPEG && (ext == ".jpg" || ext == ".jpeg") {
+		return thumb.Data, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(thumb.Data))
+	if err != nil {
+		return nil, fmt.Errorf("could not decode %s preview: %w", thumb.Format, err)
+	}
+	if size > 0 {
+		img = resizeToFit(img, size)
+	}
+
+	var buf bytes.Buffer
+	switch ext {
+	case ".png":
+		err = png.Encode(&buf, img)
+	case ".jpg", ".jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	default:
+		return nil, fmt.Errorf("unsupported output format %q (use .jpg or .png)", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// resizeToFit scales img so that its longest side is size pixels, keeping
+// the aspect ratio. Each output pixel averages the source pixels it covers,
+// which gives clean results when shrinking; enlarging repeats pixels.
+func resizeToFit(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	srcW, srcH := b.Dx(), b.Dy()
+	if srcW == 0 || srcH == 0 {
+		return img
+	}
+
+	dstW, dstH := size, size
+	if srcW > srcH {
+		dstH = max(1, srcH*size/srcW)
+	} else {
+		dstW = max(1, srcW*size/srcH)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := b.Min.Y + y*srcH/dstH
+		y1 := max(y0+1, b.Min.Y+(y+1)*srcH/dstH)
+		for x := 0; x < dstW; x++ {
+			x0 := b.Min.X + x*srcW/dstW
+			x1 := max(x0+1, b.Min.X+(x+1)*srcW/dstW)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
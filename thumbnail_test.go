@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testImage returns a w x h image, red on the left half and blue on the right.
+func testImage(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= w/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestResizeToFit(t *testing.T) {
+	tests := []struct {
+		w, h, size   int
+		wantW, wantH int
+	}{
+		{400, 200, 100, 100, 50},
+		{200, 400, 100, 50, 100},
+		{300, 300, 64, 64, 64},
+		{1000, 3, 100, 100, 1}, // never collapses to zero
+		{50, 25, 200, 200, 100},
+	}
+	for _, tt := range tests {
+		got := resizeToFit(testImage(tt.w, tt.h), tt.size).Bounds()
+		if got.Dx() != tt.wantW || got.Dy() != tt.wantH {
+			t.Errorf("%dx%d in %d: got %dx%d, want %dx%d", tt.w, tt.h, tt.size, got.Dx(), got.Dy(), tt.wantW, tt.wantH)
+		}
+	}
+
+	// Averaging keeps the two halves apart.
+	small := resizeToFit(testImage(400, 200), 100)
+	if r, _, b, _ := small.At(10, 10).RGBA(); r>>8 != 255 || b != 0 {
+		t.Errorf("left pixel = %v, want red", small.At(10, 10))
+	}
+	if r, _, b, _ := small.At(90, 10).RGBA(); r != 0 || b>>8 != 255 {
+		t.Errorf("right pixel = %v, want blue", small.At(90, 10))
+	}
+}
+
+func TestEncodeThumbnail(t *testing.T) {
+	var jpg bytes.Buffer
+	if err := jpeg.Encode(&jpg, testImage(400, 200), nil); err != nil {
+		t.Fatal(err)
+	}
+	thumb := Thumbnail{Format: "JPEG", Data: jpg.Bytes()}
+
+	// Unchanged JPEG: the embedded bytes, untouched.
+	data, err := encodeThumbnail(thumb, ".jpg", 0)
+	if err != nil || !bytes.Equal(data, thumb.Data) {
+		t.Errorf("passthrough: err %v, bytes equal %v", err, bytes.Equal(data, thumb.Data))
+	}
+
+	// PNG conversion at the original size.
+	data, err = encodeThumbnail(thumb, ".png", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("PNG output does not decode: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 400 || b.Dy() != 200 {
+		t.Errorf("PNG size = %v, want 400x200", b)
+	}
+
+	// Resized JPEG keeps the aspect ratio.
+	data, err = encodeThumbnail(thumb, ".jpeg", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width != 100 || cfg.Height != 50 {
+		t.Errorf("resized JPEG = %dx%d (%v), want 100x50", cfg.Width, cfg.Height, err)
+	}
+
+	if _, err := encodeThumbnail(thumb, ".gif", 0); err == nil {
+		t.Error("GIF output: want an error")
+	}
+	if _, err := encodeThumbnail(Thumbnail{Format: "JPEG", Data: []byte("garbage")}, ".png", 0); err == nil {
+		t.Error("garbage preview: want an error")
+	}
+}
+
+func TestSelectThumbnail(t *testing.T) {
+	page1 := Thumbnail{Page: 1, Format: "JPEG"}
+	page2 := Thumbnail{Page: 2, Format: "JPEG"}
+	doc := Thumbnail{Page: 0, Format: "JPEG"}
+	tests := []struct {
+		name   string
+		thumbs []Thumbnail
+		page   int
+		want   Thumbnail
+		ok     bool
+	}{
+		{"document thumbnail", []Thumbnail{page1, doc, page2}, 0, doc, true},
+		{"page preview", []Thumbnail{doc, page1, page2}, 2, page2, true},
+		{"falls back to the first page", []Thumbnail{page1, page2}, 0, page1, true},
+		{"missing page", []Thumbnail{doc, page1}, 3, Thumbnail{}, false},
+		{"no previews", nil, 0, Thumbnail{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := selectThumbnail(tt.thumbs, tt.page)
+		if ok != tt.ok || got.Page != tt.want.Page {
+			t.Errorf("%s: got page %d, %v; want page %d, %v", tt.name, got.Page, ok, tt.want.Page, tt.ok)
+		}
+	}
+}
+
+func TestWriteThumbnailFile(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "Brochure.jpg")
+	if err := writeThumbnailFile(p, []byte("first"), false); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeThumbnailFile(p, []byte("second"), false); err == nil {
+		t.Error("default output overwrote an existing file")
+	}
+	if err := writeThumbnailFile(p, []byte("third"), true); err != nil {
+		t.Errorf("explicit -o: %v", err)
+	}
+	if data, _ := os.ReadFile(p); string(data) != "third" {
+		t.Errorf("file holds %q, want third", data)
+	}
+}
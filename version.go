@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version is an InDesign version. Files only record the major (and a
+// coarse minor); point releases come from XMP or the aid instruction.
+type Version struct {
+	Major uint32
+	Minor uint32
+	Patch uint32
+}
+
+// firstYearMajor and firstYearName anchor the year-based names: since
+// InDesign 2020 (major 15) every major is named after the following year.
+const (
+	firstYearMajor = 15
+	firstYearName  = 2020
+)
+
+// numericVersionPattern matches "19", "19.2" and "19.2.1".
+var numericVersionPattern = regexp.MustCompile(`^(\d+)(?:\.(\d+))?(?:\.(\d+))?$`)
+
+// parseVersion parses a numeric version ("19.2.1"), a year ("2024") or a
+// marketing name from the catalog ("CC 2019", "CS6").
+func parseVersion(s string) (Version, error) {
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return Version{}, fmt.Errorf("empty version")
+	}
+
+	// Marketing names first, so "2024" is the year and not major 2024.
+	if major, ok := catalog.majorForName(s); ok {
+		return Version{Major: major}, nil
+	}
+
+	m := numericVersionPattern.FindStringSubmatch(s)
+	if m == nil {
+		return Version{}, fmt.Errorf("unknown InDesign version %q", s)
+	}
+	var parts [3]uint32
+	for i, p := range m[1:] {
+		if p == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid InDesign version %q: %w", s, err)
+		}
+		parts[i] = uint32(n)
+	}
+	v := Version{Major: parts[0], Minor: parts[1], Patch: parts[2]}
+
+	// A bare year newer than the catalog, e.g. "2031".
+	if m[2] == "" && v.Major >= firstYearName {
+		v = Version{Major: v.Major - firstYearName + firstYearMajor}
+	}
+	if v.Major == 0 || v.Major > maxPlausibleMajor {
+		return Version{}, fmt.Errorf("implausible InDesign version %q", s)
+	}
+	return v, nil
+}
+
+// Compare returns -1, 0 or +1 depending on whether v is older than, the
+// same as, or newer than o.
+func (v Version) Compare(o Version) int {
+	for _, d := range [][2]uint32{{v.Major, o.Major}, {v.Minor, o.Minor}, {v.Patch, o.Patch}} {
+		switch {
+		case d[0] < d[1]:
+			return -1
+		case d[0] > d[1]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// Name returns the marketing name, e.g. "2024" or "CC 2019". Majors that
+// are not in the catalog get a synthesized name: the year for majors of
+// the year-based era (2020 and later), "v<major>" for older ones.
+func (v Version) Name() string {
+	if e, ok := catalog.lookup(v.Major); ok && e.Name != "" {
+		return e.Name
+	}
+	if v.Major >= firstYearMajor {
+		return strconv.Itoa(int(v.Major-firstYearMajor) + firstYearName)
+	}
+	return fmt.Sprintf("v%d", v.Major)
+}
+
+// Numeric returns the numeric version, e.g. "19.2" or "19.2.1". The patch
+// level is left out when it is zero.
+func (v Version) Numeric() string {
+	if v.Patch != 0 {
+		return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	}
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// String returns both names, e.g. "2024 (19.2)".
+func (v Version) String() string {
+	return fmt.Sprintf("%s (%s)", v.Name(), v.Numeric())
+}
+
+// Version returns the version recorded in the header.
+func (h Header) Version() Version {
+	return Version{Major: h.MajorVersion, Minor: h.MinorVersion}
+}
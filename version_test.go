@@ -0,0 +1,66 @@
+package main
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Version
+	}{
+		{"19.2.1", Version{19, 2, 1}},
+		{"19.2", Version{19, 2, 0}},
+		{"19", Version{19, 0, 0}},
+		{"2024", Version{19, 0, 0}},
+		{"CC 2019", Version{14, 0, 0}},
+		{"cc  2019", Version{14, 0, 0}},
+		{"CS6", Version{8, 0, 0}},
+		{"2031", Version{26, 0, 0}},
+	}
+	for _, tt := range tests {
+		got, err := parseVersion(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("parseVersion(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "CS9", "2019", "0", "19.x", "100"} {
+		if v, err := parseVersion(in); err == nil {
+			t.Errorf("parseVersion(%q) = %v, want an error", in, v)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	ordered := []Version{{8, 0, 0}, {19, 0, 0}, {19, 2, 0}, {19, 2, 1}, {19, 10, 0}, {20, 0, 0}}
+	for i := range ordered {
+		for j := range ordered {
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if got := ordered[i].Compare(ordered[j]); got != want {
+				t.Errorf("%v.Compare(%v) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+}
+
+func TestVersionNames(t *testing.T) {
+	tests := []struct {
+		v    Version
+		want string
+	}{
+		{Version{19, 2, 0}, "2024 (19.2)"},
+		{Version{14, 0, 0}, "CC 2019 (14.0)"},
+		{Version{8, 0, 3}, "CS6 (8.0.3)"},
+		{Version{24, 1, 0}, "2029 (24.1)"}, // not in the catalog
+		{Version{2, 0, 0}, "v2 (2.0)"},
+	}
+	for _, tt := range tests {
+		if got := tt.v.String(); got != tt.want {
+			t.Errorf("%#v.String() = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
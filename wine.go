@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// On Linux, InDesign runs inside a Wine prefix: a folder with a fake
+// Windows drive in "drive_c". Prefixes are created by plain Wine
+// (~/.wine), CrossOver (bottles in ~/.cxoffice), Bottles and Lutris, and
+// each of them may use its own wine binary. All paths here are hostFS
+// names, so the code can be tested with fake trees.
+
+// winePrefix is one Wine prefix and how to run programs in it.
+type winePrefix struct {
+	// Path is the prefix folder, the parent of drive_c.
+	Path string
+	// Kind is "Wine", "CrossOver", "Bottles" or "Lutris".
+	Kind string
+	// Wine is the command that runs a Windows program in the prefix,
+	// without the program, e.g. ["/opt/cxoffice/bin/wine", "--bottle", "Adobe"].
+	Wine []string
+	// SetPrefix tells whether WINEPREFIX must point at Path; CrossOver
+	// selects the bottle with --bottle instead.
+	SetPrefix bool
+}
+
+// Default locations, relative to the home folder.
+const (
+	crossOverBottles  = ".cxoffice"
+	crossOverUserWine = "cxoffice/bin/wine"
+	bottlesData       = ".local/share/bottles"
+	bottlesFlatpak    = ".var/app/com.usebottles.bottles/data/bottles"
+	lutrisGames       = ".config/lutris/games"
+	lutrisRunners     = ".local/share/lutris/runners/wine"
+)
+
+// crossOverWine is the wine binary of a system-wide CrossOver install,
+// relative to the filesystem root.
+const crossOverWine = "opt/cxoffice/bin/wine"
+
+// findWinePrefixes returns every Wine prefix of the user whose home folder
+// is home. getenv is os.Getenv, or a fake in tests.
+func findWinePrefixes(fsys fs.FS, home string, getenv func(string) string) []winePrefix {
+	var prefixes []winePrefix
+	add := func(p winePrefix) {
+		if _, err := fs.Stat(fsys, path.Join(p.Path, "drive_c")); err != nil {
+			return
+		}
+		for _, seen := range prefixes {
+			if seen.Path == p.Path {
+				return
+			}
+		}
+		prefixes = append(prefixes, p)
+	}
+
+	// Plain Wine: $WINEPREFIX, else ~/.wine.
+	defaultPrefix := path.Join(home, ".wine")
+	if env := getenv("WINEPREFIX"); env != "" {
+		defaultPrefix = fsName(env)
+	}
+	add(winePrefix{Path: defaultPrefix, Kind: "Wine", Wine: []string{"wine"}, SetPrefix: true})
+
+	// CrossOver: one prefix per bottle, run with CrossOver's own wine from
+	// a per-user install (~/cxoffice) or else a system-wide one (/opt).
+	cxWine := ""
+	for _, bin := range []string{path.Join(home, crossOverUserWine), crossOverWine} {
+		if isFileFS(fsys, bin) {
+			cxWine = bin
+			break
+		}
+	}
+	for _, dir := range subdirs(fsys, path.Join(home, crossOverBottles)) {
+		p := winePrefix{Path: dir, Kind: "CrossOver", Wine: []string{"wine"}, SetPrefix: true}
+		if cxWine != "" {
+			p.Wine = []string{hostPath(cxWine), "--bottle", path.Base(dir)}
+			p.SetPrefix = false
+		}
+		add(p)
+	}
+
+	// Bottles, native and Flatpak: the runner is named in bottle.yml.
+	for _, data := range []string{path.Join(home, bottlesData), path.Join(home, bottlesFlatpak)} {
+		for _, dir := range subdirs(fsys, path.Join(data, "bottles")) {
+			wine := []string{"wine"}
+			if runner := yamlValue(fsys, path.Join(dir, "bottle.yml"), "", "Runner"); runner != "" {
+				if bin := path.Join(data, "runners", runner, "bin", "wine"); isFileFS(fsys, bin) {
+					wine = []string{hostPath(bin)}
+				}
+			}
+			add(winePrefix{Path: dir, Kind: "Bottles", Wine: wine, SetPrefix: true})
+		}
+	}
+
+	// Lutris: every game configuration names its prefix and wine version.
+	games, _ := fs.Glob(fsys, path.Join(home, lutrisGames, "*.yml"))
+	for _, game := range games {
+		prefix := yamlValue(fsys, game, "game", "prefix")
+		if prefix == "" {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(prefix, "~/"); ok {
+			prefix = path.Join(home, rest)
+		} else {
+			prefix = fsName(prefix)
+		}
+		wine := []string{"wine"}
+		if version := yamlValue(fsys, game, "wine", "version"); version != "" {
+			if bin := path.Join(home, lutrisRunners, version, "bin", "wine"); isFileFS(fsys, bin) {
+				wine = []string{hostPath(bin)}
+			}
+		}
+		add(winePrefix{Path: prefix, Kind: "Lutris", Wine: wine, SetPrefix: true})
+	}
+	return prefixes
+}
+
+// subdirs returns the folders inside dir, or nothing if dir is missing.
+func subdirs(fsys fs.FS, dir string) []string {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, e := range entries {
+		if e.IsDir() {
+			dirs = append(dirs, path.Join(dir, e.Name()))
+		}
+	}
+	return dirs
+}
+
+// isFileFS reports whether name is a regular file in fsys.
+func isFileFS(fsys fs.FS, name string) bool {
+	info, err := fs.Stat(fsys, name)
+	return err == nil && !info.IsDir()
+}
+
+// yamlValue returns the value of key in the top-level section of a simple
+// YAML file, or of a top-level key when section is empty. Bottles and
+// Lutris only need this much, so there is no YAML dependency.
+func yamlValue(fsys fs.FS, name, section, key string) string {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	current := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indented := line[0] == ' ' || line[0] == '\t'
+		k, v, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		if !indented {
+			current = k
+		}
+		if k != key || (section == "") == indented || (section != "" && current != section) {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(v), `"'`)
+	}
+	return ""
+}
+
+// wineDiscoverer finds InDesign inside Wine prefixes by scanning their
+// Program Files folders like on Windows.
+type wineDiscoverer struct {
+	fsys     fs.FS
+	prefixes []winePrefix
+}
+
+func (d wineDiscoverer) Name() string { return "Wine prefixes" }
+
+func (d wineDiscoverer) Discover() ([]Install, error) {
+	var installs []Install
+	var errs []error
+	for _, p := range d.prefixes {
+		for _, programFiles := range []string{"Program Files", "Program Files (x86)"} {
+			scan := folderScanDiscoverer{fsys: d.fsys, goos: "windows", dir: path.Join(p.Path, "drive_c", programFiles, "Adobe")}
+			found, err := scan.Discover()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s prefix %s: %w", p.Kind, hostPath(p.Path), err))
+			}
+			installs = append(installs, found...)
+		}
+	}
+	return installs, errors.Join(errs...)
+}
+
+// prefixForApp returns the prefix that contains appPath, an OS path.
+func prefixForApp(prefixes []winePrefix, appPath string) (winePrefix, bool) {
+	name := fsName(appPath)
+	for _, p := range prefixes {
+		if strings.HasPrefix(name, p.Path+"/drive_c/") {
+			return p, true
+		}
+	}
+	return winePrefix{}, false
+}
+
+// prefixAbove finds the prefix of an install outside the known prefixes,
+// such as one listed in the user catalog, by walking up from appPath to
+// the nearest drive_c folder. It is run with plain wine.
+func prefixAbove(fsys fs.FS, appPath string) (winePrefix, bool) {
+	for dir := path.Dir(fsName(appPath)); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if path.Base(dir) != "drive_c" {
+			continue
+		}
+		if info, err := fs.Stat(fsys, dir); err != nil || !info.IsDir() {
+			return winePrefix{}, false
+		}
+		return winePrefix{Path: path.Dir(dir), Kind: "Wine", Wine: []string{"wine"}, SetPrefix: true}, true
+	}
+	return winePrefix{}, false
+}
+
+// windowsPath translates a Unix path for a program running in prefix p:
+// files inside drive_c become C:\ paths, everything else goes through
+// Wine's Z: drive, which maps the Unix root.
+func windowsPath(p winePrefix, unixPath string) string {
+	name := fsName(unixPath)
+	if rest, ok := strings.CutPrefix(name, p.Path+"/drive_c/"); ok {
+		return `C:\` + strings.ReplaceAll(rest, "/", `\`)
+	}
+	return `Z:\` + strings.ReplaceAll(name, "/", `\`)
+}
+
+// wineCommand builds the command that opens filePath with the InDesign at
+// appPath inside prefix p.
+func wineCommand(p winePrefix, appPath, filePath string) *exec.Cmd {
+	args := append(append([]string{}, p.Wine[1:]...), windowsPath(p, appPath), windowsPath(p, filePath))
+	cmd := exec.Command(p.Wine[0], args...)
+	if p.SetPrefix {
+		cmd.Env = append(os.Environ(), "WINEPREFIX="+hostPath(p.Path))
+	}
+	return cmd
+}
+
+// wineLaunchCommand finds the prefix of appPath on this machine and builds
+// the command that opens filePath with it.
+func wineLaunchCommand(appPath, filePath string) (*exec.Cmd, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("could not find home folder: %w", err)
+	}
+	p, ok := prefixForApp(findWinePrefixes(hostFS{}, fsName(home), os.Getenv), appPath)
+	if !ok {
+		p, ok = prefixAbove(hostFS{}, appPath)
+	}
+	if !ok {
+		return nil, fmt.Errorf("'%s' is not inside a Wine prefix (no drive_c folder above it)", appPath)
+	}
+	return wineCommand(p, appPath, filePath), nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// wineHome is a home folder with one InDesign in each kind of prefix.
+var wineHome = fstest.MapFS{
+	// Plain Wine.
+	"home/u/.wine/drive_c/Program Files/Adobe/Adobe InDesign 2024/InDesign.exe": {},
+	// CrossOver, with CrossOver installed.
+	"home/u/.cxoffice/Adobe/drive_c/Program Files/Adobe/Adobe InDesign 2023/InDesign.exe": {},
+	"opt/cxoffice/bin/wine": {},
+	// Bottles, with its runner.
+	"home/u/.local/share/bottles/bottles/Design/bottle.yml":                                                      {Data: []byte("Name: Design\nRunner: caffe-7.20\nArch: win64\n")},
+	"home/u/.local/share/bottles/bottles/Design/drive_c/Program Files/Adobe/Adobe InDesign CC 2019/InDesign.exe": {},
+	"home/u/.local/share/bottles/runners/caffe-7.20/bin/wine":                                                    {},
+	// Lutris, with a prefix outside the usual places and a missing runner.
+	"home/u/.config/lutris/games/indesign-1700000000.yml": {Data: []byte(
+		"game:\n  exe: ~/Games/indesign/drive_c/x.exe\n  prefix: ~/Games/indesign\n" +
+			"system:\n  prefix: /nowhere\nwine:\n  version: lutris-GE-8-26\n")},
+	"home/u/Games/indesign/drive_c/Program Files (x86)/Adobe/Adobe InDesign CS6/InDesign.exe": {},
+	// Not a prefix.
+	"home/u/.cxoffice/Settings/config.ini": {},
+}
+
+func TestFindWinePrefixes(t *testing.T) {
+	prefixes := findWinePrefixes(wineHome, "home/u", func(string) string { return "" })
+	want := []winePrefix{
+		{Path: "home/u/.wine", Kind: "Wine", Wine: []string{"wine"}, SetPrefix: true},
+		{Path: "home/u/.cxoffice/Adobe", Kind: "CrossOver", Wine: []string{filepath.FromSlash("/opt/cxoffice/bin/wine"), "--bottle", "Adobe"}},
+		{Path: "home/u/.local/share/bottles/bottles/Design", Kind: "Bottles", Wine: []string{filepath.FromSlash("/home/u/.local/share/bottles/runners/caffe-7.20/bin/wine")}, SetPrefix: true},
+		{Path: "home/u/Games/indesign", Kind: "Lutris", Wine: []string{"wine"}, SetPrefix: true},
+	}
+	if len(prefixes) != len(want) {
+		t.Fatalf("found %d prefixes %v, want %d", len(prefixes), prefixes, len(want))
+	}
+	for i, p := range prefixes {
+		w := want[i]
+		if p.Path != w.Path || p.Kind != w.Kind || strings.Join(p.Wine, " ") != strings.Join(w.Wine, " ") || p.SetPrefix != w.SetPrefix {
+			t.Errorf("prefix %d = %+v, want %+v", i, p, w)
+		}
+	}
+
+	// A per-user CrossOver install in ~/cxoffice.
+	userCrossOver := fstest.MapFS{
+		"home/u/.cxoffice/Adobe/drive_c/Program Files/Adobe/Adobe InDesign 2023/InDesign.exe": {},
+		"home/u/cxoffice/bin/wine": {},
+	}
+	prefixes = findWinePrefixes(userCrossOver, "home/u", func(string) string { return "" })
+	if len(prefixes) != 1 || strings.Join(prefixes[0].Wine, " ") != filepath.FromSlash("/home/u/cxoffice/bin/wine")+" --bottle Adobe" {
+		t.Errorf("prefixes with CrossOver in the home folder = %+v", prefixes)
+	}
+
+	// $WINEPREFIX replaces ~/.wine.
+	prefixes = findWinePrefixes(wineHome, "home/u", func(string) string { return "/home/u/Games/indesign" })
+	if prefixes[0].Path != "home/u/Games/indesign" || prefixes[0].Kind != "Wine" {
+		t.Errorf("first prefix with WINEPREFIX = %+v", prefixes[0])
+	}
+}
+
+func TestWineDiscoverer(t *testing.T) {
+	prefixes := findWinePrefixes(wineHome, "home/u", func(string) string { return "" })
+	found, err := discoverAll([]Discoverer{wineDiscoverer{fsys: wineHome, prefixes: prefixes}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for major, prefix := range map[uint32]string{19: ".wine", 18: ".cxoffice", 14: "bottles", 8: "Games"} {
+		if !strings.Contains(found[major], prefix) {
+			t.Errorf("major %d = %q, want an install in %s", major, found[major], prefix)
+		}
+	}
+}
+
+func TestPrefixAbove(t *testing.T) {
+	fsys := fstest.MapFS{
+		"srv/wine/adobe/drive_c/Apps/InDesign 2024/InDesign.exe": {},
+		"srv/apps/InDesign.exe":                                  {},
+	}
+	p, ok := prefixAbove(fsys, filepath.FromSlash("/srv/wine/adobe/drive_c/Apps/InDesign 2024/InDesign.exe"))
+	if !ok || p.Path != "srv/wine/adobe" || p.Wine[0] != "wine" || !p.SetPrefix {
+		t.Errorf("prefix = %+v, %v; want srv/wine/adobe run with wine", p, ok)
+	}
+	if p, ok := prefixAbove(fsys, filepath.FromSlash("/srv/apps/InDesign.exe")); ok {
+		t.Errorf("install outside any prefix: got %+v", p)
+	}
+}
+
+func TestWineCommand(t *testing.T) {
+	prefixes := findWinePrefixes(wineHome, "home/u", func(string) string { return "" })
+	app := filepath.FromSlash("/home/u/.cxoffice/Adobe/drive_c/Program Files/Adobe/Adobe InDesign 2023/InDesign.exe")
+	p, ok := prefixForApp(prefixes, app)
+	if !ok || p.Kind != "CrossOver" {
+		t.Fatalf("prefix for %s = %+v, %v", app, p, ok)
+	}
+	cmd := wineCommand(p, app, filepath.FromSlash("/srv/jobs/Brochure.indd"))
+	want := []string{filepath.FromSlash("/opt/cxoffice/bin/wine"), "--bottle", "Adobe",
+		`C:\Program Files\Adobe\Adobe InDesign 2023\InDesign.exe`, `Z:\srv\jobs\Brochure.indd`}
+	if strings.Join(cmd.Args, "|") != strings.Join(want, "|") {
+		t.Errorf("args = %q, want %q", cmd.Args, want)
+	}
+	if cmd.Env != nil {
+		t.Error("CrossOver command sets WINEPREFIX")
+	}
+
+	p, _ = prefixForApp(prefixes, filepath.FromSlash("/home/u/.wine/drive_c/Program Files/Adobe/Adobe InDesign 2024/InDesign.exe"))
+	cmd = wineCommand(p, "/home/u/.wine/drive_c/x.exe", "/home/u/.wine/drive_c/users/u/Desktop/a.indd")
+	if last := cmd.Args[len(cmd.Args)-1]; last != `C:\users\u\Desktop\a.indd` {
+		t.Errorf("file inside the prefix = %q, want a C: path", last)
+	}
+	if env := cmd.Env[len(cmd.Env)-1]; env != "WINEPREFIX="+filepath.FromSlash("/home/u/.wine") {
+		t.Errorf("env = %q, want WINEPREFIX", env)
+	}
+
+	if _, ok := prefixForApp(prefixes, "/usr/bin/true"); ok {
+		t.Error("found a prefix for a path outside every prefix")
+	}
+}